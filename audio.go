@@ -0,0 +1,507 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+)
+
+//audioInfo holds the stream properties of an audio file
+type audioInfo struct {
+	Container  string `json:"container"`
+	Codec      string `json:"codec"`
+	SampleRate int    `json:"sample_rate"`
+	//BitDepth is only known for lossless codecs
+	BitDepth int `json:"bit_depth,omitempty"`
+	Channels int `json:"channels"`
+	//Bitrate is the average bitrate in kbit/s, 0 when unknown
+	Bitrate  int  `json:"bitrate"`
+	VBR      bool `json:"vbr,omitempty"`
+	Lossless bool `json:"lossless"`
+}
+
+var errUnknownAudioFormat = errors.New("unknown audio format")
+
+//readAudioInfo reads the stream properties from the headers of a FLAC, MP3, WAV, OGG or MP4 file of size bytes.
+//ext is the file name extension: MP3 files have no magic number and are only looked for behind an
+//ID3v2 tag, a frame sync at the start or a ".mp3" extension, as frame syncs show up in any data.
+func readAudioInfo(r io.ReadSeeker, size int64, ext string) (*audioInfo, error) {
+	offset, err := skipID3v2(r)
+	if err != nil {
+		return nil, err
+	}
+
+	head := make([]byte, 12)
+	if _, err := io.ReadFull(r, head); err != nil {
+		return nil, err
+	}
+
+	switch {
+	case bytes.Equal(head[:4], []byte("fLaC")):
+		return readFLACInfo(r, offset, size)
+	case bytes.Equal(head[:4], []byte("RIFF")) && bytes.Equal(head[8:12], []byte("WAVE")):
+		return readWAVInfo(r)
+	case bytes.Equal(head[:4], []byte("OggS")):
+		return readOggInfo(r, size)
+	case bytes.Equal(head[4:8], []byte("ftyp")):
+		return readMP4Info(r, size)
+	}
+
+	if _, ok := parseMP3Frame(binary.BigEndian.Uint32(head[:4])); offset == 0 && ok == false && strings.EqualFold(ext, ".mp3") == false {
+		return nil, errUnknownAudioFormat
+	}
+
+	return readMP3Info(r, offset, size)
+}
+
+//skipID3v2 moves r past a leading ID3v2 tag and returns the offset of the data behind it
+func skipID3v2(r io.ReadSeeker) (int64, error) {
+	header := make([]byte, 10)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return 0, err
+	}
+
+	var offset int64
+	if bytes.Equal(header[:3], []byte("ID3")) {
+		offset = 10 + (int64(header[6]&0x7f)<<21 | int64(header[7]&0x7f)<<14 | int64(header[8]&0x7f)<<7 | int64(header[9]&0x7f))
+		if header[5]&0x10 != 0 {
+			offset += 10
+		}
+	}
+
+	_, err := r.Seek(offset, io.SeekStart)
+
+	return offset, err
+}
+
+//flacStreamInfo decodes a STREAMINFO metadata block
+func flacStreamInfo(b []byte, info *audioInfo) (totalSamples int64) {
+	info.SampleRate = int(b[10])<<12 | int(b[11])<<4 | int(b[12])>>4
+	info.Channels = int(b[12]>>1&0x07) + 1
+	info.BitDepth = (int(b[12]&0x01)<<4 | int(b[13])>>4) + 1
+
+	return int64(b[13]&0x0f)<<32 | int64(binary.BigEndian.Uint32(b[14:18]))
+}
+
+func readFLACInfo(r io.ReadSeeker, offset int64, size int64) (*audioInfo, error) {
+	info := &audioInfo{Container: "FLAC", Codec: "FLAC", Lossless: true}
+
+	//metadata blocks follow the "fLaC" marker, audio frames follow the last block
+	position := offset + 4
+	var totalSamples int64
+	for {
+		if _, err := r.Seek(position, io.SeekStart); err != nil {
+			return nil, err
+		}
+
+		header := make([]byte, 4)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, err
+		}
+		length := int64(header[1])<<16 | int64(header[2])<<8 | int64(header[3])
+
+		if header[0]&0x7f == 0 {
+			block := make([]byte, 34)
+			if _, err := io.ReadFull(r, block); err != nil {
+				return nil, err
+			}
+			totalSamples = flacStreamInfo(block, info)
+		}
+
+		position += 4 + length
+		if header[0]&0x80 != 0 {
+			break
+		}
+	}
+
+	if info.SampleRate == 0 {
+		return nil, errors.New("flac: missing STREAMINFO")
+	}
+
+	info.Bitrate = averageBitrate(size-position, totalSamples, info.SampleRate)
+
+	return info, nil
+}
+
+//averageBitrate returns the bitrate in kbit/s of audioBytes holding samples at sampleRate
+func averageBitrate(audioBytes int64, samples int64, sampleRate int) int {
+	if samples <= 0 || sampleRate <= 0 || audioBytes <= 0 {
+		return 0
+	}
+
+	return int(audioBytes * 8 * int64(sampleRate) / samples / 1000)
+}
+
+func readWAVInfo(r io.ReadSeeker) (*audioInfo, error) {
+	info := &audioInfo{Container: "WAV", Lossless: true}
+
+	for {
+		header := make([]byte, 8)
+		if _, err := io.ReadFull(r, header); err != nil {
+			return nil, errors.New("wav: missing fmt chunk")
+		}
+		length := int64(binary.LittleEndian.Uint32(header[4:8]))
+
+		if bytes.Equal(header[:4], []byte("fmt ")) == false {
+			//chunks are padded to an even size
+			if _, err := r.Seek(length+length%2, io.SeekCurrent); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		format := make([]byte, 16)
+		if _, err := io.ReadFull(r, format); err != nil {
+			return nil, err
+		}
+
+		switch binary.LittleEndian.Uint16(format[0:2]) {
+		case 1, 0xfffe:
+			info.Codec = "PCM"
+		case 3:
+			info.Codec = "PCM float"
+		default:
+			info.Codec = "compressed"
+			info.Lossless = false
+		}
+		info.Channels = int(binary.LittleEndian.Uint16(format[2:4]))
+		info.SampleRate = int(binary.LittleEndian.Uint32(format[4:8]))
+		info.Bitrate = int(binary.LittleEndian.Uint32(format[8:12])) * 8 / 1000
+		info.BitDepth = int(binary.LittleEndian.Uint16(format[14:16]))
+
+		return info, nil
+	}
+}
+
+func readOggInfo(r io.ReadSeeker, size int64) (*audioInfo, error) {
+	if _, err := r.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	//the first page holds the identification header as its only packet
+	header := make([]byte, 27)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+	segments := make([]byte, header[26])
+	if _, err := io.ReadFull(r, segments); err != nil {
+		return nil, err
+	}
+	var length = 0
+	for _, s := range segments {
+		length += int(s)
+	}
+	packet := make([]byte, length)
+	if _, err := io.ReadFull(r, packet); err != nil {
+		return nil, err
+	}
+
+	info := &audioInfo{Container: "OGG"}
+	switch {
+	case len(packet) >= 30 && bytes.HasPrefix(packet, []byte("\x01vorbis")):
+		info.Codec = "Vorbis"
+		info.VBR = true
+		info.Channels = int(packet[11])
+		info.SampleRate = int(binary.LittleEndian.Uint32(packet[12:16]))
+	case len(packet) >= 19 && bytes.HasPrefix(packet, []byte("OpusHead")):
+		//Opus always decodes at 48 kHz
+		info.Codec = "Opus"
+		info.VBR = true
+		info.Channels = int(packet[9])
+		info.SampleRate = 48000
+	case len(packet) >= 51 && bytes.HasPrefix(packet, []byte("\x7fFLAC")):
+		info.Codec = "FLAC"
+		info.Lossless = true
+		flacStreamInfo(packet[17:51], info)
+	default:
+		return nil, errUnknownAudioFormat
+	}
+
+	if samples, err := lastOggGranule(r, size); err == nil {
+		info.Bitrate = averageBitrate(size, samples, info.SampleRate)
+	}
+
+	return info, nil
+}
+
+//lastOggGranule returns the granule position of the last page, the stream length in samples
+func lastOggGranule(r io.ReadSeeker, size int64) (int64, error) {
+	tail := int64(65536)
+	if tail > size {
+		tail = size
+	}
+	if _, err := r.Seek(size-tail, io.SeekStart); err != nil {
+		return 0, err
+	}
+
+	b := make([]byte, tail)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return 0, err
+	}
+
+	i := bytes.LastIndex(b, []byte("OggS"))
+	if i < 0 || i+14 > len(b) {
+		return 0, errors.New("ogg: no last page")
+	}
+
+	return int64(binary.LittleEndian.Uint64(b[i+6 : i+14])), nil
+}
+
+//mp4ContainerBoxes are the boxes walked down to the sample description
+var mp4ContainerBoxes = map[string]bool{"moov": true, "trak": true, "mdia": true, "minf": true, "stbl": true}
+
+func readMP4Info(r io.ReadSeeker, size int64) (*audioInfo, error) {
+	info := &audioInfo{Container: "MP4"}
+
+	var mdatSize, duration, timescale int64
+	var walk func(start int64, end int64) error
+	walk = func(start int64, end int64) error {
+		for position := start; position+8 <= end; {
+			if _, err := r.Seek(position, io.SeekStart); err != nil {
+				return err
+			}
+
+			header := make([]byte, 8)
+			if _, err := io.ReadFull(r, header); err != nil {
+				return err
+			}
+			boxSize, boxType, headerSize := int64(binary.BigEndian.Uint32(header[0:4])), string(header[4:8]), int64(8)
+			switch boxSize {
+			case 0:
+				boxSize = end - position
+			case 1:
+				large := make([]byte, 8)
+				if _, err := io.ReadFull(r, large); err != nil {
+					return err
+				}
+				boxSize, headerSize = int64(binary.BigEndian.Uint64(large)), 16
+			}
+			if boxSize < headerSize {
+				return errors.New("mp4: bad box size")
+			}
+
+			content := position + headerSize
+			switch {
+			case mp4ContainerBoxes[boxType]:
+				if err := walk(content, position+boxSize); err != nil {
+					return err
+				}
+			case boxType == "mdat":
+				mdatSize += boxSize - headerSize
+			case boxType == "mdhd" && info.Codec == "":
+				b := make([]byte, 32)
+				if _, err := io.ReadFull(r, b); err != nil {
+					return err
+				}
+				if b[0] == 1 {
+					timescale, duration = int64(binary.BigEndian.Uint32(b[20:24])), int64(binary.BigEndian.Uint64(b[24:32]))
+				} else {
+					timescale, duration = int64(binary.BigEndian.Uint32(b[12:16])), int64(binary.BigEndian.Uint32(b[16:20]))
+				}
+			case boxType == "stsd" && info.Codec == "":
+				if err := readMP4SampleEntry(r, info); err != nil {
+					return err
+				}
+			}
+
+			position += boxSize
+		}
+
+		return nil
+	}
+
+	if err := walk(0, size); err != nil {
+		return nil, err
+	}
+
+	if info.Codec == "" {
+		return nil, errors.New("mp4: no audio sample description")
+	}
+
+	if timescale > 0 && duration > 0 {
+		info.Bitrate = int(mdatSize * 8 * timescale / duration / 1000)
+	}
+
+	return info, nil
+}
+
+//readMP4SampleEntry reads the first sample entry of a stsd box, r being at its content
+func readMP4SampleEntry(r io.ReadSeeker, info *audioInfo) error {
+	//version, flags and entry count, then the entry box header and the audio sample entry
+	b := make([]byte, 8+8+28)
+	if _, err := io.ReadFull(r, b); err != nil {
+		return err
+	}
+	entry := b[16:]
+
+	info.Channels = int(binary.BigEndian.Uint16(entry[16:18]))
+	info.SampleRate = int(binary.BigEndian.Uint32(entry[24:28]) >> 16)
+
+	switch string(b[12:16]) {
+	case "mp4a":
+		info.Codec = "AAC"
+	case "alac":
+		info.Codec = "ALAC"
+		info.Lossless = true
+
+		//the alac box holds the real bit depth and sample rate
+		alac := make([]byte, 8+28)
+		if _, err := io.ReadFull(r, alac); err == nil && string(alac[4:8]) == "alac" {
+			info.BitDepth = int(alac[17])
+			info.SampleRate = int(binary.BigEndian.Uint32(alac[32:36]))
+		} else {
+			info.BitDepth = int(binary.BigEndian.Uint16(entry[18:20]))
+		}
+	default:
+		info.Codec = string(b[12:16])
+	}
+
+	return nil
+}
+
+var mp3Bitrates = [2][3][16]int{
+	//MPEG-1 layers I, II and III
+	{
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	//MPEG-2 and 2.5 layers I, II and III
+	{
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+var mp3SampleRates = map[int][3]int{
+	3: {44100, 48000, 32000}, //MPEG-1
+	2: {22050, 24000, 16000}, //MPEG-2
+	0: {11025, 12000, 8000},  //MPEG-2.5
+}
+
+//mp3Frame is a decoded MPEG audio frame header
+type mp3Frame struct {
+	version    int
+	layer      int
+	bitrate    int
+	sampleRate int
+	mono       bool
+	length     int
+}
+
+func parseMP3Frame(h uint32) (mp3Frame, bool) {
+	f := mp3Frame{version: int(h >> 19 & 3), layer: 4 - int(h>>17&3)}
+	bitrateIndex, rateIndex := int(h>>12&0xf), int(h>>10&3)
+
+	if h>>21 != 0x7ff || f.version == 1 || f.layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || rateIndex == 3 {
+		return f, false
+	}
+
+	table := 0
+	if f.version != 3 {
+		table = 1
+	}
+	f.bitrate = mp3Bitrates[table][f.layer-1][bitrateIndex]
+	f.sampleRate = mp3SampleRates[f.version][rateIndex]
+	f.mono = h>>6&3 == 3
+
+	padding := int(h >> 9 & 1)
+	switch {
+	case f.layer == 1:
+		f.length = (12*f.bitrate*1000/f.sampleRate + padding) * 4
+	case f.layer == 3 && f.version != 3:
+		f.length = 72*f.bitrate*1000/f.sampleRate + padding
+	default:
+		f.length = 144*f.bitrate*1000/f.sampleRate + padding
+	}
+
+	return f, true
+}
+
+//samplesPerFrame returns the number of samples an MPEG audio frame holds
+func (f mp3Frame) samplesPerFrame() int64 {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && f.version != 3:
+		return 576
+	}
+
+	return 1152
+}
+
+func readMP3Info(r io.ReadSeeker, offset int64, size int64) (*audioInfo, error) {
+	if _, err := r.Seek(offset, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	//look for two consecutive frames among the first bytes, as padding may precede the first one
+	b := make([]byte, 65536)
+	n, _ := io.ReadFull(r, b)
+	b = b[:n]
+
+	for i := 0; i+4 <= len(b); i++ {
+		if b[i] != 0xff {
+			continue
+		}
+
+		f, ok := parseMP3Frame(binary.BigEndian.Uint32(b[i : i+4]))
+		if ok == false {
+			continue
+		}
+		if next := i + f.length; next+4 <= len(b) {
+			if _, ok := parseMP3Frame(binary.BigEndian.Uint32(b[next : next+4])); ok == false {
+				continue
+			}
+		}
+
+		info := &audioInfo{
+			Container:  "MP3",
+			Codec:      []string{"", "MP1", "MP2", "MP3"}[f.layer],
+			SampleRate: f.sampleRate,
+			Channels:   2,
+			Bitrate:    f.bitrate,
+		}
+		if f.mono == true {
+			info.Channels = 1
+		}
+
+		//a Xing or VBRI header in the first frame gives the frame count of VBR files
+		sideInfo := 32
+		switch {
+		case f.version == 3 && f.mono == true:
+			sideInfo = 17
+		case f.version != 3 && f.mono == false:
+			sideInfo = 17
+		case f.version != 3:
+			sideInfo = 9
+		}
+
+		frame := b[i:]
+		var frames int64
+		switch {
+		case len(frame) >= 4+sideInfo+12 && bytes.Equal(frame[4+sideInfo:8+sideInfo], []byte("Xing")):
+			info.VBR = true
+			fallthrough
+		case len(frame) >= 4+sideInfo+12 && bytes.Equal(frame[4+sideInfo:8+sideInfo], []byte("Info")):
+			if binary.BigEndian.Uint32(frame[8+sideInfo:12+sideInfo])&1 != 0 {
+				frames = int64(binary.BigEndian.Uint32(frame[12+sideInfo : 16+sideInfo]))
+			}
+		case len(frame) >= 36+18 && bytes.Equal(frame[36:40], []byte("VBRI")):
+			info.VBR = true
+			frames = int64(binary.BigEndian.Uint32(frame[50:54]))
+		}
+
+		if info.VBR == true && frames > 0 {
+			audioBytes := size - offset - int64(i)
+			info.Bitrate = averageBitrate(audioBytes, frames*f.samplesPerFrame(), f.sampleRate)
+		}
+
+		return info, nil
+	}
+
+	return nil, errUnknownAudioFormat
+}
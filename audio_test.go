@@ -0,0 +1,271 @@
+package main
+
+import (
+	"bytes"
+	"encoding/binary"
+	"math/rand"
+	"reflect"
+	"testing"
+)
+
+func be16(v int) []byte {
+	b := make([]byte, 2)
+	binary.BigEndian.PutUint16(b, uint16(v))
+	return b
+}
+
+func be32(v int) []byte {
+	b := make([]byte, 4)
+	binary.BigEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func le16(v int) []byte {
+	b := make([]byte, 2)
+	binary.LittleEndian.PutUint16(b, uint16(v))
+	return b
+}
+
+func le32(v int) []byte {
+	b := make([]byte, 4)
+	binary.LittleEndian.PutUint32(b, uint32(v))
+	return b
+}
+
+func join(parts ...[]byte) []byte {
+	return bytes.Join(parts, nil)
+}
+
+//flacStreamInfoBlock builds a 34 bytes STREAMINFO block
+func flacStreamInfoBlock(sampleRate int, channels int, bitDepth int, totalSamples int64) []byte {
+	b := make([]byte, 34)
+	packed := uint64(sampleRate)<<44 | uint64(channels-1)<<41 | uint64(bitDepth-1)<<36 | uint64(totalSamples)
+	binary.BigEndian.PutUint64(b[10:18], packed)
+	return b
+}
+
+func flacFile(audioBytes int) []byte {
+	return join(
+		[]byte("fLaC"),
+		//PADDING block, then the last block: STREAMINFO
+		[]byte{0x01, 0, 0, 4}, make([]byte, 4),
+		[]byte{0x80, 0, 0, 34}, flacStreamInfoBlock(44100, 2, 16, 44100),
+		make([]byte, audioBytes),
+	)
+}
+
+func id3v2Tag(padding int) []byte {
+	return join([]byte("ID3"), []byte{4, 0, 0}, []byte{0, 0, byte(padding >> 7), byte(padding & 0x7f)}, make([]byte, padding))
+}
+
+func wavFile(format int, bitDepth int) []byte {
+	fmtChunk := join(le16(format), le16(2), le32(44100), le32(44100*2*bitDepth/8), le16(2*bitDepth/8), le16(bitDepth))
+	return join(
+		[]byte("RIFF"), le32(0), []byte("WAVE"),
+		//odd sized chunks are padded
+		[]byte("LIST"), le32(3), []byte{1, 2, 3, 0},
+		[]byte("fmt "), le32(len(fmtChunk)), fmtChunk,
+		[]byte("data"), le32(8), make([]byte, 8),
+	)
+}
+
+func oggPage(granule int64, packet []byte) []byte {
+	header := make([]byte, 27)
+	copy(header, "OggS")
+	binary.LittleEndian.PutUint64(header[6:14], uint64(granule))
+	if packet == nil {
+		return header
+	}
+	header[26] = 1
+	return join(header, []byte{byte(len(packet))}, packet)
+}
+
+//oggFile builds an identification page and a last page, size bytes long
+func oggFile(packet []byte, samples int64, size int) []byte {
+	first := oggPage(0, packet)
+	last := oggPage(samples, nil)
+	return join(first, make([]byte, size-len(first)-len(last)), last)
+}
+
+func vorbisPacket() []byte {
+	return join([]byte("\x01vorbis"), le32(0), []byte{2}, le32(44100), make([]byte, 12), []byte{0xb8, 1})
+}
+
+func opusPacket() []byte {
+	return join([]byte("OpusHead"), []byte{1, 2}, le16(312), le32(44100), le16(0), []byte{0})
+}
+
+func oggFLACPacket() []byte {
+	return join([]byte("\x7fFLAC"), []byte{1, 0}, be16(1), []byte("fLaC"), []byte{0x80, 0, 0, 34}, flacStreamInfoBlock(96000, 2, 24, 96000))
+}
+
+func mp4Box(boxType string, content ...[]byte) []byte {
+	body := join(content...)
+	return join(be32(8+len(body)), []byte(boxType), body)
+}
+
+//mp4File builds a file holding one audio track of one second at sampleRate
+func mp4File(entryType string, sampleRate int, extra []byte, mdat int) []byte {
+	mdhd := mp4Box("mdhd", make([]byte, 12), be32(sampleRate), be32(sampleRate), make([]byte, 4))
+	hdlr := mp4Box("hdlr", make([]byte, 8), []byte("soun"), make([]byte, 12))
+	entry := mp4Box(entryType, make([]byte, 6), be16(1), make([]byte, 8), be16(2), be16(16), make([]byte, 4), be32(sampleRate<<16), extra)
+	stsd := mp4Box("stsd", make([]byte, 4), be32(1), entry)
+
+	return join(
+		mp4Box("ftyp", []byte("M4A "), be32(0), []byte("M4A ")),
+		mp4Box("moov", mp4Box("trak", mp4Box("mdia", mdhd, hdlr, mp4Box("minf", mp4Box("stbl", stsd))))),
+		mp4Box("mdat", make([]byte, mdat)),
+	)
+}
+
+func alacBox(bitDepth int, sampleRate int) []byte {
+	return mp4Box("alac", make([]byte, 4), be32(4096), []byte{0, byte(bitDepth), 40, 10, 14, 2}, be16(255), be32(0), be32(0), be32(sampleRate))
+}
+
+//mp3File repeats a frame of header h count times, the first frame holding tag at offset
+func mp3File(h uint32, length int, count int, offset int, tag []byte) []byte {
+	frame := make([]byte, length)
+	binary.BigEndian.PutUint32(frame, h)
+
+	first := append([]byte{}, frame...)
+	copy(first[offset:], tag)
+
+	var b []byte
+	b = append(b, first...)
+	for i := 1; i < count; i++ {
+		b = append(b, frame...)
+	}
+
+	return b
+}
+
+const (
+	//MPEG-1 layer III, 128 kbit/s, 44100 Hz, 417 bytes frames
+	mp3Stereo = 0xfffb9000
+	mp3Mono   = 0xfffb90c0
+	//MPEG-2 layer III, 64 kbit/s, 22050 Hz, 208 bytes frames
+	mp3MPEG2 = 0xfff38000
+)
+
+func xingTag(name string, frames int) []byte {
+	return join([]byte(name), be32(1), be32(frames))
+}
+
+func vbriTag(frames int) []byte {
+	return join([]byte("VBRI"), be16(1), be16(0), be16(75), be32(0), be32(frames))
+}
+
+var audioFixtures = []struct {
+	name string
+	data []byte
+	want *audioInfo
+}{
+	{"flac", flacFile(17640), &audioInfo{Container: "FLAC", Codec: "FLAC", SampleRate: 44100, BitDepth: 16, Channels: 2, Bitrate: 141, Lossless: true}},
+	{"flac behind id3v2", join(id3v2Tag(200), flacFile(17640)), &audioInfo{Container: "FLAC", Codec: "FLAC", SampleRate: 44100, BitDepth: 16, Channels: 2, Bitrate: 141, Lossless: true}},
+	{"wav pcm", wavFile(1, 16), &audioInfo{Container: "WAV", Codec: "PCM", SampleRate: 44100, BitDepth: 16, Channels: 2, Bitrate: 1411, Lossless: true}},
+	{"wav float", wavFile(3, 32), &audioInfo{Container: "WAV", Codec: "PCM float", SampleRate: 44100, BitDepth: 32, Channels: 2, Bitrate: 2822, Lossless: true}},
+	{"wav compressed", wavFile(0x55, 16), &audioInfo{Container: "WAV", Codec: "compressed", SampleRate: 44100, BitDepth: 16, Channels: 2, Bitrate: 1411}},
+	{"ogg vorbis", oggFile(vorbisPacket(), 44100, 16000), &audioInfo{Container: "OGG", Codec: "Vorbis", SampleRate: 44100, Channels: 2, Bitrate: 128, VBR: true}},
+	{"ogg opus", oggFile(opusPacket(), 48000, 12000), &audioInfo{Container: "OGG", Codec: "Opus", SampleRate: 48000, Channels: 2, Bitrate: 96, VBR: true}},
+	{"ogg flac", oggFile(oggFLACPacket(), 96000, 40000), &audioInfo{Container: "OGG", Codec: "FLAC", SampleRate: 96000, BitDepth: 24, Channels: 2, Bitrate: 320, Lossless: true}},
+	{"mp4 aac", mp4File("mp4a", 44100, nil, 16000), &audioInfo{Container: "MP4", Codec: "AAC", SampleRate: 44100, Channels: 2, Bitrate: 128}},
+	{"mp4 alac", mp4File("alac", 96000, alacBox(24, 96000), 16000), &audioInfo{Container: "MP4", Codec: "ALAC", SampleRate: 96000, BitDepth: 24, Channels: 2, Bitrate: 128, Lossless: true}},
+	{"mp3 cbr", mp3File(mp3Stereo, 417, 3, 0, nil), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 2, Bitrate: 128}},
+	{"mp3 mono", mp3File(mp3Mono, 417, 3, 0, nil), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 1, Bitrate: 128}},
+	{"mp3 mpeg-2", mp3File(mp3MPEG2, 208, 3, 0, nil), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 22050, Channels: 2, Bitrate: 64}},
+	{"mp3 behind id3v2 and padding", join(id3v2Tag(100), make([]byte, 7), mp3File(mp3Stereo, 417, 3, 0, nil)), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 2, Bitrate: 128}},
+	{"mp3 xing", mp3File(mp3Stereo, 417, 101, 4+32, xingTag("Xing", 100)), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 2, Bitrate: 128, VBR: true}},
+	{"mp3 xing mono", mp3File(mp3Mono, 417, 101, 4+17, xingTag("Xing", 100)), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 1, Bitrate: 128, VBR: true}},
+	{"mp3 info", mp3File(mp3Stereo, 417, 3, 4+32, xingTag("Info", 3)), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 2, Bitrate: 128}},
+	{"mp3 vbri", mp3File(mp3Stereo, 417, 101, 36, vbriTag(100)), &audioInfo{Container: "MP3", Codec: "MP3", SampleRate: 44100, Channels: 2, Bitrate: 128, VBR: true}},
+}
+
+func TestReadAudioInfo(t *testing.T) {
+	for _, fixture := range audioFixtures {
+		got, err := readAudioInfo(bytes.NewReader(fixture.data), int64(len(fixture.data)), "")
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", fixture.name, err)
+			continue
+		}
+		if reflect.DeepEqual(got, fixture.want) == false {
+			t.Errorf("%s: got %+v, want %+v", fixture.name, got, fixture.want)
+		}
+	}
+}
+
+func TestReadAudioInfoErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", nil},
+		{"text", []byte("this is not an audio file at all")},
+		{"flac without streaminfo", join([]byte("fLaC"), []byte{0x81, 0, 0, 4}, make([]byte, 4))},
+		{"wav without fmt", join([]byte("RIFF"), le32(0), []byte("WAVE"), []byte("data"), le32(4), make([]byte, 4))},
+		{"ogg unknown codec", oggFile([]byte("\x80theora and more bytes to be long enough"), 10, 200)},
+		{"mp4 without audio", join(mp4Box("ftyp", []byte("M4A "), be32(0)), mp4Box("moov"))},
+		{"mp4 bad box size", join(mp4Box("ftyp", []byte("M4A "), be32(0)), be32(4), []byte("moov"))},
+		{"mp3 bad bitrate", mp3File(0xfffbf000, 417, 3, 0, nil)},
+	}
+
+	for _, test := range tests {
+		if info, err := readAudioInfo(bytes.NewReader(test.data), int64(len(test.data)), ""); err == nil {
+			t.Errorf("%s: expected an error, got %+v", test.name, info)
+		}
+	}
+}
+
+//TestReadAudioInfoTruncated reads every prefix of every fixture, which must fail or succeed without panicking
+func TestReadAudioInfoTruncated(t *testing.T) {
+	for _, fixture := range audioFixtures {
+		for n := 0; n < len(fixture.data) && n < 2048; n++ {
+			data := fixture.data[:n]
+			readAudioInfo(bytes.NewReader(data), int64(n), ".mp3")
+		}
+	}
+}
+
+//TestReadAudioInfoGarbage reads random bytes, alone and behind every magic number
+func TestReadAudioInfoGarbage(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	magics := [][]byte{nil, []byte("fLaC"), []byte("RIFF\x00\x00\x00\x00WAVE"), []byte("OggS"), []byte("\x00\x00\x00\x10ftyp"), []byte("ID3\x04\x00\x00"), {0xff, 0xfb}}
+
+	for i := 0; i < 2000; i++ {
+		data := make([]byte, random.Intn(512))
+		random.Read(data)
+		data = join(magics[i%len(magics)], data)
+
+		readAudioInfo(bytes.NewReader(data), int64(len(data)), ".mp3")
+	}
+}
+
+//TestReadAudioInfoMP3Detection checks frame syncs are only looked for in files that look like MP3
+func TestReadAudioInfoMP3Detection(t *testing.T) {
+	frames := mp3File(mp3Stereo, 417, 3, 0, nil)
+	padded := join(make([]byte, 7), frames)
+	aiff := join([]byte("FORM"), be32(len(frames)+4), []byte("AIFF"), frames)
+
+	tests := []struct {
+		name string
+		data []byte
+		ext  string
+		mp3  bool
+	}{
+		{"frame sync at the start", frames, ".wma", true},
+		{"padding behind id3v2", join(id3v2Tag(100), padded), "", true},
+		{"padding in a .mp3 file", padded, ".mp3", true},
+		{"padding in a .MP3 file", padded, ".MP3", true},
+		{"padding in an unknown file", padded, ".aac", false},
+		{"aiff holding frame syncs", aiff, ".aif", false},
+	}
+
+	for _, test := range tests {
+		info, err := readAudioInfo(bytes.NewReader(test.data), int64(len(test.data)), test.ext)
+		if test.mp3 == true && (err != nil || info.Container != "MP3") {
+			t.Errorf("%s: got %+v, %v, want MP3", test.name, info, err)
+		}
+		if test.mp3 == false && err != errUnknownAudioFormat {
+			t.Errorf("%s: got %+v, %v, want %v", test.name, info, err, errUnknownAudioFormat)
+		}
+	}
+}
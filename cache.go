@@ -0,0 +1,241 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+//cacheVersion changes whenever the cached values change, dropping older entries
+const cacheVersion = "4"
+
+var (
+	cacheTracksBucket = []byte("tracks")
+	cacheMetaBucket   = []byte("meta")
+	cacheVersionKey   = []byte("version")
+)
+
+//cacheEntry holds the tags of a file as long as its size and modification time do not change
+type cacheEntry struct {
+	Size    int64     `json:"size"`
+	ModTime int64     `json:"mod_time"`
+	Tags    tagValues `json:"tags"`
+}
+
+//scanCache stores parsed tags keyed by absolute file path
+type scanCache struct {
+	db      *bolt.DB
+	pending map[string][]byte
+}
+
+//CacheStats describes the content of a cache file
+type CacheStats struct {
+	Path    string
+	Size    int64
+	Entries int
+	Stale   int
+}
+
+func defaultCachePath() string {
+	dir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+
+	return filepath.Join(dir, "audio-lib-tools", "scan.db")
+}
+
+//openCache opens or creates the cache file at path
+func openCache(path string) (*scanCache, error) {
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Update(func(tx *bolt.Tx) error {
+		meta, err := tx.CreateBucketIfNotExists(cacheMetaBucket)
+		if err != nil {
+			return err
+		}
+
+		if string(meta.Get(cacheVersionKey)) != cacheVersion {
+			if tx.Bucket(cacheTracksBucket) != nil {
+				if err := tx.DeleteBucket(cacheTracksBucket); err != nil {
+					return err
+				}
+			}
+
+			if err := meta.Put(cacheVersionKey, []byte(cacheVersion)); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.CreateBucketIfNotExists(cacheTracksBucket)
+
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &scanCache{db: db, pending: map[string][]byte{}}, nil
+}
+
+func cacheKey(path string) []byte {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		abs = path
+	}
+
+	return []byte(abs)
+}
+
+func (e *cacheEntry) fresh(info os.FileInfo) bool {
+	return e.Size == info.Size() && e.ModTime == info.ModTime().UnixNano()
+}
+
+//get returns the cached tags of path when the file did not change. It is safe for concurrent use.
+func (c *scanCache) get(path string, info os.FileInfo) (*trackTags, bool) {
+	var entry *cacheEntry
+
+	c.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(cacheTracksBucket).Get(cacheKey(path))
+		if data == nil {
+			return nil
+		}
+
+		e := &cacheEntry{}
+		if err := json.Unmarshal(data, e); err == nil {
+			entry = e
+		}
+
+		return nil
+	})
+
+	if entry == nil || entry.fresh(info) == false {
+		return nil, false
+	}
+
+	return &trackTags{entry.Tags}, true
+}
+
+//put queues the tags of path; queued entries are written by flush
+func (c *scanCache) put(path string, info os.FileInfo, t *trackTags) error {
+	data, err := json.Marshal(cacheEntry{
+		Size:    info.Size(),
+		ModTime: info.ModTime().UnixNano(),
+		Tags:    t.v,
+	})
+	if err != nil {
+		return err
+	}
+
+	c.pending[string(cacheKey(path))] = data
+	if len(c.pending) >= 1000 {
+		return c.flush()
+	}
+
+	return nil
+}
+
+//flush writes queued entries in one transaction
+func (c *scanCache) flush() error {
+	if len(c.pending) == 0 {
+		return nil
+	}
+
+	err := c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(cacheTracksBucket)
+		for k, v := range c.pending {
+			if err := b.Put([]byte(k), v); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	c.pending = map[string][]byte{}
+
+	return err
+}
+
+func (c *scanCache) close() error {
+	err := c.flush()
+	if cerr := c.db.Close(); err == nil {
+		err = cerr
+	}
+
+	return err
+}
+
+//isStale tells if the file of a cache entry was removed or changed
+func isStale(key []byte, data []byte) bool {
+	info, err := os.Stat(string(key))
+	if err != nil {
+		return true
+	}
+
+	e := &cacheEntry{}
+	if err := json.Unmarshal(data, e); err != nil {
+		return true
+	}
+
+	return e.fresh(info) == false
+}
+
+//prune removes entries of removed or changed files and returns how many were removed
+func (c *scanCache) prune() (int, error) {
+	var stale [][]byte
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(cacheTracksBucket).ForEach(func(k, v []byte) error {
+			if isStale(k, v) == true {
+				stale = append(stale, append([]byte(nil), k...))
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = c.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(cacheTracksBucket)
+		for _, k := range stale {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	return len(stale), err
+}
+
+//stats counts entries and stale entries of the cache
+func (c *scanCache) stats() (CacheStats, error) {
+	s := CacheStats{Path: c.db.Path()}
+
+	err := c.db.View(func(tx *bolt.Tx) error {
+		s.Size = tx.Size()
+
+		return tx.Bucket(cacheTracksBucket).ForEach(func(k, v []byte) error {
+			s.Entries++
+			if isStale(k, v) == true {
+				s.Stale++
+			}
+			return nil
+		})
+	})
+
+	return s, err
+}
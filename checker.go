@@ -2,274 +2,342 @@ package main
 
 import (
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
-
-	tag "github.com/dhowden/tag"
-	color "github.com/fatih/color"
 )
 
-func check(root string, checkTracks bool, checkAlbums bool, limit int, onlyErrors bool) {
-	var tracks []string
-	var albums []string
-	var errorCount = 0
-
-	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if info.IsDir() == true {
-			if isAlbumDirectory(path) == true {
-				albums = append(albums, path)
-			}
+//SectionSummary counts checked items and findings of a check section
+type SectionSummary struct {
+	Checked    int `json:"checked"`
+	Errors     int `json:"errors"`
+	Warnings   int `json:"warnings"`
+	Suppressed int `json:"suppressed"`
+}
 
-			return nil
+func (s *SectionSummary) add(findings []Finding, suppressed int) {
+	s.Checked++
+	s.Suppressed += suppressed
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			s.Errors++
+		} else {
+			s.Warnings++
 		}
+	}
+}
 
-		if isAudioFile(filepath.Ext(path)) == false {
-			return nil
-		}
+//Summary counts checked albums and tracks and their findings
+type Summary struct {
+	Albums     SectionSummary `json:"albums"`
+	Tracks     SectionSummary `json:"tracks"`
+	Library    SectionSummary `json:"library"`
+	ReadErrors int            `json:"read_errors"`
+}
 
-		tracks = append(tracks, path)
+//Errors counts error findings of all sections
+func (s Summary) Errors() int {
+	return s.Albums.Errors + s.Tracks.Errors + s.Library.Errors
+}
 
-		return nil
-	})
+//Warnings counts warning findings of all sections
+func (s Summary) Warnings() int {
+	return s.Albums.Warnings + s.Tracks.Warnings + s.Library.Warnings
+}
 
-	if checkAlbums == true {
-		color.Green("\n// Check Albums //\n")
+//Exit codes of the check command
+const (
+	exitClean    = 0
+	exitFindings = 1
+	exitUsage    = 2
+)
 
-		var totalCheckedAlbums = 0
-		var totalErroredAlbums = 0
-		var totalWarningAlbums = 0
-		for _, albumPath := range albums {
-			errors, warnings, _ := checkAlbumRules(albumPath, onlyErrors)
-			if len(errors) > 0 || len(warnings) > 0 {
-				color.Cyan("Check directory %s", albumPath)
-			}
+//parseFailOn reads the --fail-on severity; 0 means never fail on severity
+func parseFailOn(s string) (Severity, error) {
+	if sanitizeString(s) == "none" {
+		return 0, nil
+	}
 
-			for _, reason := range errors {
-				color.Red(reason)
-			}
+	return parseSeverity(s)
+}
 
-			for _, reason := range warnings {
-				color.Yellow(reason)
-			}
+//checkFailed tells if findings reach failOn or exceed a threshold; negative thresholds are disabled
+func checkFailed(summary Summary, failOn Severity, maxErrors int, maxWarnings int) bool {
+	if maxErrors >= 0 {
+		if summary.Errors() > maxErrors {
+			return true
+		}
+	} else if failOn != 0 && summary.Errors() > 0 {
+		return true
+	}
 
-			errorCount += len(errors) + len(warnings)
-			totalCheckedAlbums++
-			totalErroredAlbums += len(errors)
-			totalWarningAlbums += len(warnings)
+	if maxWarnings >= 0 && summary.Warnings() > maxWarnings {
+		return true
+	}
 
-			if limit > 0 && errorCount >= limit {
-				red := color.New(color.FgRed)
-				whiteBackground := red.Add(color.BgWhite)
-				whiteBackground.Println("Error count limit reached")
+	return failOn == SeverityWarning && maxWarnings < 0 && summary.Warnings() > 0
+}
 
-				color.Green("\nTotal checked album: %d\n", totalCheckedAlbums)
-				color.Red("Total errored album: %d\n", totalErroredAlbums)
-				color.Yellow("Total warning album: %d\n", totalWarningAlbums)
+//checkOptions holds the check command settings
+type checkOptions struct {
+	Tracks     bool
+	Albums     bool
+	Library    bool
+	Limit      int
+	OnlyErrors bool
+	Jobs       int
+	Config     *Config
+	Cache      *scanCache
+}
 
-				return
-			}
-		}
+func check(root string, opts checkOptions, reporter Reporter) (Summary, error) {
+	var findingCount = 0
+	var limitReached = false
+	var summary Summary
+	var ignores = &ignoreList{}
 
-		color.Green("\nTotal checked album: %d\n", totalCheckedAlbums)
-		color.Red("Total errored album: %d\n", totalErroredAlbums)
-		color.Yellow("Total warning album: %d\n", totalWarningAlbums)
+	//rules are configured first, so configuration errors show up before a long scan
+	configuredTrackRules, err := opts.Config.trackRules()
+	if err != nil {
+		return summary, err
 	}
 
-	if checkTracks == true {
-		color.Green("\n// Check Tracks //\n")
+	configuredAlbumRules, err := opts.Config.albumRules()
+	if err != nil {
+		return summary, err
+	}
 
-		var totalCheckedTracks = 0
-		var totalErroredTracks = 0
-		var totalWarningTracks = 0
-		for _, trackPath := range tracks {
+	configuredLibraryRules, err := opts.Config.libraryRules()
+	if err != nil {
+		return summary, err
+	}
 
-			errors, warnings, _ := checkTrackRules(trackPath, onlyErrors)
-			if len(errors) > 0 || len(warnings) > 0 {
-				color.Cyan("Check file %s", trackPath)
-			}
+	lib, err := scanLibrary(root, opts.Jobs, opts.Cache)
+	if err != nil {
+		return summary, err
+	}
+	summary.ReadErrors = lib.ReadErrors
+	lib.variousArtists = opts.Config.variousArtistsDictionary()
+	lib.suspiciousMatchers = suspiciousMatchers(configuredTrackRules)
 
-			for _, reason := range errors {
-				color.Red(reason)
-			}
+	for _, path := range lib.IgnoreFiles {
+		if err := ignores.add(path); err != nil {
+			return summary, err
+		}
+	}
 
-			for _, reason := range warnings {
-				color.Yellow(reason)
-			}
+	var activeTrackRules []TrackRule
+	var trackRulesInfo []RuleInfo
+	for _, rule := range configuredTrackRules {
+		if opts.OnlyErrors == true && rule.Severity() != SeverityError {
+			continue
+		}
 
-			totalCheckedTracks++
-			errorCount += len(errors) + len(warnings)
-			totalWarningTracks += len(warnings)
-			totalErroredTracks += len(errors)
-			if limit > 0 && errorCount >= limit {
-				red := color.New(color.FgRed)
-				whiteBackground := red.Add(color.BgWhite)
-				whiteBackground.Print("Error count limit reached")
+		activeTrackRules = append(activeTrackRules, rule)
+		trackRulesInfo = append(trackRulesInfo, RuleInfo{rule.ID(), rule.Description(), rule.Severity()})
+	}
 
-				break
-			}
+	var activeAlbumRules []AlbumRule
+	var albumRulesInfo []RuleInfo
+	for _, rule := range configuredAlbumRules {
+		if opts.OnlyErrors == true && rule.Severity() != SeverityError {
+			continue
 		}
 
-		color.Green("\nTotal checked tracks: %d\n", totalCheckedTracks)
-		color.Red("Total errored tracks: %d\n", totalErroredTracks)
-		color.Yellow("Total warning tracks: %d\n", totalWarningTracks)
+		activeAlbumRules = append(activeAlbumRules, rule)
+		albumRulesInfo = append(albumRulesInfo, RuleInfo{rule.ID(), rule.Description(), rule.Severity()})
 	}
-}
 
-func checkTrackRules(path string, onlyErrors bool) ([]string, []string, error) {
-	var errors []string
-	var warnings []string
+	var activeLibraryRules []LibraryRule
+	var libraryRulesInfo []RuleInfo
+	for _, rule := range configuredLibraryRules {
+		if opts.OnlyErrors == true && rule.Severity() != SeverityError {
+			continue
+		}
 
-	m, err := getTrackMetaData(path)
-	if err != nil {
-		fmt.Printf("error reading file: %v\n", err)
-		return nil, nil, nil
+		activeLibraryRules = append(activeLibraryRules, rule)
+		libraryRulesInfo = append(libraryRulesInfo, RuleInfo{rule.ID(), rule.Description(), rule.Severity()})
 	}
 
-	var errored bool
-	var reason string
+	//the limit only applies to reported findings, all findings are counted
+	report := func(section string, path string, findings []Finding) {
+		if limitReached == true {
+			return
+		}
 
-	errored, reason = missingTrackTagRule(path, m)
-	if errored == true {
-		errors = append(errors, reason)
-	}
+		reporter.Report(section, path, findings)
 
-	errored, reason = missingAlbumTagRule(path, m)
-	if errored == true {
-		errors = append(errors, reason)
+		findingCount += len(findings)
+		if opts.Limit > 0 && findingCount >= opts.Limit {
+			reporter.LimitReached(section)
+			limitReached = true
+		}
 	}
 
-	errored, reason = missingAlbumArtistTagRule(path, m)
-	if errored == true && onlyErrors == false {
-		warnings = append(errors, reason)
-	}
+	if opts.Albums == true {
+		reporter.StartSection(sectionAlbums, albumRulesInfo)
 
-	errored, reason = missingArtistTagRule(path, m)
-	if errored == true {
-		errors = append(errors, reason)
-	}
+		results := make([][]Finding, len(lib.Albums))
+		forEachOrdered(len(lib.Albums), opts.Jobs, func(i int) {
+			results[i] = checkAlbumRules(lib.Albums[i], activeAlbumRules)
+		}, func(i int) bool {
+			albumFindings, suppressed := ignores.filter(results[i])
+			results[i] = nil
 
-	errored, reason = unknowTrackTagRule(path, m)
-	if errored == true && onlyErrors == false {
-		warnings = append(errors, reason)
-	}
+			report(sectionAlbums, lib.Albums[i].Path, albumFindings)
+			summary.Albums.add(albumFindings, suppressed)
 
-	errored, reason = unknowAlbumTagRule(path, m)
-	if errored == true && onlyErrors == false {
-		warnings = append(errors, reason)
-	}
+			return true
+		})
 
-	errored, reason = unknowAlbumArtistTagRule(path, m)
-	if errored == true && onlyErrors == false {
-		warnings = append(errors, reason)
+		reporter.EndSection(sectionAlbums, summary.Albums)
 	}
 
-	errored, reason = suspiciousVariousArtistsAlbumArtistTagRule(path, m)
-	if errored == true && onlyErrors == false {
-		warnings = append(errors, reason)
-	}
+	if opts.Tracks == true {
+		reporter.StartSection(sectionTracks, trackRulesInfo)
 
-	errored, reason = unknowArtistTagRule(path, m)
-	if errored == true && onlyErrors == false {
-		warnings = append(errors, reason)
-	}
+		results := make([][]Finding, len(lib.Tracks))
+		forEachOrdered(len(lib.Tracks), opts.Jobs, func(i int) {
+			results[i] = checkTrackRules(lib.Tracks[i], activeTrackRules)
+		}, func(i int) bool {
+			trackFindings, suppressed := ignores.filter(results[i])
+			results[i] = nil
 
-	return errors, warnings, nil
-}
+			report(sectionTracks, lib.Tracks[i].Path, trackFindings)
+			summary.Tracks.add(trackFindings, suppressed)
 
-func checkAlbumRules(path string, onlyErrors bool) ([]string, []string, error) {
-	var errors []string
-	var warnings []string
-	var dirTracks []tag.Metadata
+			return true
+		})
 
-	err := filepath.Walk(path, func(path string, info os.FileInfo, err error) error {
+		reporter.EndSection(sectionTracks, summary.Tracks)
+	}
 
-		if info.IsDir() == true {
-			return nil
-		}
+	if opts.Library == true {
+		reporter.StartSection(sectionLibrary, libraryRulesInfo)
 
-		if isAudioFile(filepath.Ext(path)) == false {
-			return nil
+		libraryFindings, suppressed := ignores.filter(checkLibraryRules(lib, activeLibraryRules))
+
+		//library findings are about many files, they are reported by path
+		var paths []string
+		byPath := map[string][]Finding{}
+		for _, f := range libraryFindings {
+			if _, exists := byPath[f.Path]; exists == false {
+				paths = append(paths, f.Path)
+			}
+			byPath[f.Path] = append(byPath[f.Path], f)
+		}
+		for _, path := range paths {
+			report(sectionLibrary, path, byPath[path])
 		}
 
-		file, err := os.Open(path)
-		defer file.Close()
+		summary.Library.add(libraryFindings, suppressed)
 
-		m, err := tag.ReadFrom(file)
-		if err != nil {
-			fmt.Printf("error reading file: %v\n", err)
-			return nil
-		}
+		reporter.EndSection(sectionLibrary, summary.Library)
+	}
 
-		dirTracks = append(dirTracks, m)
+	return summary, reporter.Finish(summary)
+}
 
-		return nil
-	})
+func init() {
+	RegisterTrackRule(NewTrackRule("missing-title", "Track title is empty.", SeverityError, missingTrackTagRule))
+	RegisterTrackRule(NewTrackRule("missing-album", "Album name is empty.", SeverityError, missingAlbumTagRule))
+	RegisterTrackRule(NewTrackRule("missing-album-artist", "Album artist name is empty.", SeverityWarning, missingAlbumArtistTagRule))
+	RegisterTrackRule(NewTrackRule("missing-artist", "Artist name is empty.", SeverityError, missingArtistTagRule))
+	RegisterTrackRule(NewTrackRuleWithOptions("suspicious-various-artists", "Album artist is a misspelled Various Artists.", SeverityWarning, RuleOptions{"canonical": "Various Artists"}, suspiciousVariousArtistsAlbumArtistTagRule))
 
-	if err != nil {
-		panic(err)
+	RegisterAlbumRule(NewAlbumRule("multiple-album-names", "Directory tracks have different album names.", SeverityError, multipleAlbumNameRule))
+	RegisterAlbumRule(NewAlbumRule("multiple-album-artists", "Directory tracks have different album artists.", SeverityError, multipleAlbumArtistsRule))
+	RegisterAlbumRule(NewAlbumRule("duplicate-track-number", "Directory contains the same disc and track number twice.", SeverityError, noSameTrackNumberRule))
+}
+
+func checkTrackRules(track *TrackFile, rules []TrackRule) []Finding {
+	var findings []Finding
+	for _, rule := range rules {
+		findings = append(findings, stampFindings(rule.ID(), rule.Severity(), track.Path, rule.Check(track))...)
 	}
 
-	var errored bool
-	var reason string
+	return findings
+}
 
-	errored, reason = multipleAlbumNameRule(path, dirTracks)
-	if errored == true {
-		errors = append(errors, reason)
+func checkAlbumRules(album *AlbumDir, rules []AlbumRule) []Finding {
+	var findings []Finding
+	for _, rule := range rules {
+		findings = append(findings, stampFindings(rule.ID(), rule.Severity(), album.Path, rule.Check(album))...)
 	}
 
-	errored, reason = multipleAlbumArtistsRule(path, dirTracks)
-	if errored == true {
-		errors = append(errors, reason)
+	return findings
+}
+
+func checkLibraryRules(lib *Library, rules []LibraryRule) []Finding {
+	var findings []Finding
+	for _, rule := range rules {
+		findings = append(findings, stampFindings(rule.ID(), rule.Severity(), lib.Root, rule.Check(lib))...)
 	}
 
-	errored, reason = noSameTrackNumberRule(path, dirTracks)
-	if errored == true {
-		errors = append(errors, reason)
+	return findings
+}
+
+//stampFindings fills the rule identity and location rules may leave empty
+func stampFindings(ruleID string, severity Severity, path string, findings []Finding) []Finding {
+	for i := range findings {
+		if findings[i].RuleID == "" {
+			findings[i].RuleID = ruleID
+		}
+		if findings[i].Severity == 0 {
+			findings[i].Severity = severity
+		}
+		if findings[i].Path == "" {
+			findings[i].Path = path
+		}
 	}
 
-	return errors, warnings, nil
+	return findings
 }
 
-func multipleAlbumNameRule(path string, tracks []tag.Metadata) (bool, string) {
+func multipleAlbumNameRule(album *AlbumDir, options RuleOptions) []Finding {
 	var isFirst = true
 	var firstAlbumName string
-	for _, track := range tracks {
+	for _, track := range album.Tracks {
 		if isFirst == true {
 			firstAlbumName = track.Album()
 			isFirst = false
 		}
 
 		if firstAlbumName != track.Album() {
-
-			return true, fmt.Sprintf("Directory contains multiple album names (%s != %s)", firstAlbumName, track.Album())
+			return []Finding{{
+				Field:    "album",
+				Value:    track.Album(),
+				Expected: firstAlbumName,
+				Message:  fmt.Sprintf("Directory contains multiple album names (%s != %s)", firstAlbumName, track.Album()),
+			}}
 		}
 	}
 
-	return false, ""
+	return nil
 }
 
-func multipleAlbumArtistsRule(path string, tracks []tag.Metadata) (bool, string) {
+func multipleAlbumArtistsRule(album *AlbumDir, options RuleOptions) []Finding {
 	var isFirst = true
 	var firstAlbumArtist string
-	for _, track := range tracks {
+	for _, track := range album.Tracks {
 		if isFirst == true {
 			firstAlbumArtist = track.AlbumArtist()
 			isFirst = false
 		}
 
 		if firstAlbumArtist != track.AlbumArtist() {
-
-			return true, fmt.Sprintf("Directory contains multiple album artists names (%s != %s)", firstAlbumArtist, track.AlbumArtist())
+			return []Finding{{
+				Field:    "album_artist",
+				Value:    track.AlbumArtist(),
+				Expected: firstAlbumArtist,
+				Message:  fmt.Sprintf("Directory contains multiple album artists names (%s != %s)", firstAlbumArtist, track.AlbumArtist()),
+			}}
 		}
 	}
 
-	return false, ""
+	return nil
 }
 
-func noSameTrackNumberRule(path string, tracks []tag.Metadata) (bool, string) {
+func noSameTrackNumberRule(album *AlbumDir, options RuleOptions) []Finding {
 	var tracksNumbers []string
-	for _, track := range tracks {
+	for _, track := range album.Tracks {
 
 		trackNumber, _ := track.Track()
 		trackDisc, _ := track.Disc()
@@ -278,156 +346,63 @@ func noSameTrackNumberRule(path string, tracks []tag.Metadata) (bool, string) {
 		if containsString(tracksNumbers, trackDiscAndNumber) == false {
 			tracksNumbers = append(tracksNumbers, trackDiscAndNumber)
 		} else {
-			return true, "Directory contains same track number"
+			return []Finding{{
+				Field:   "track",
+				Value:   trackDiscAndNumber,
+				Message: "Directory contains same track number",
+			}}
 		}
 	}
 
-	return false, ""
+	return nil
 }
 
-func missingArtistTagRule(path string, track tag.Metadata) (bool, string) {
+func missingArtistTagRule(track *TrackFile, options RuleOptions) []Finding {
 	artistName := sanitizeString(track.Artist())
 	if "" == artistName {
-		return true, "Artist name is empty."
+		return []Finding{{Field: "artist", Value: track.Artist(), Message: "Artist name is empty."}}
 	}
 
-	return false, ""
+	return nil
 }
 
-func unknowArtistTagRule(path string, track tag.Metadata) (bool, string) {
-	artistName := sanitizeString(track.Artist())
-	if true == isUnknow(artistName) {
-		return true, fmt.Sprintf("Artist name should be unknow (%s).", track.Artist())
-	}
-
-	return false, ""
-}
-
-func missingAlbumArtistTagRule(path string, track tag.Metadata) (bool, string) {
+func missingAlbumArtistTagRule(track *TrackFile, options RuleOptions) []Finding {
 	albumArtistName := sanitizeString(track.AlbumArtist())
 	if "" == albumArtistName {
-		return true, "Album artist name is empty."
+		return []Finding{{Field: "album_artist", Value: track.AlbumArtist(), Message: "Album artist name is empty."}}
 	}
-	return false, ""
-}
 
-func unknowAlbumArtistTagRule(path string, track tag.Metadata) (bool, string) {
-	albumArtistName := sanitizeString(track.AlbumArtist())
-	if true == isUnknow(albumArtistName) {
-		return true, fmt.Sprintf("Album artist name should be unknow (%s).", track.AlbumArtist())
-	}
-
-	return false, ""
+	return nil
 }
 
-func suspiciousVariousArtistsAlbumArtistTagRule(path string, track tag.Metadata) (bool, string) {
-	albumArtistName := sanitizeString(track.AlbumArtist())
-	if true == isVariousArtists(albumArtistName) && track.AlbumArtist() != "Various Artists" {
-		return true, fmt.Sprintf("Album artist name should be Various Artists (%s).", track.AlbumArtist())
+func suspiciousVariousArtistsAlbumArtistTagRule(track *TrackFile, options RuleOptions) []Finding {
+	canonical, _ := options.String("canonical", "Various Artists")
+	if true == track.library.isVariousArtists(track.AlbumArtist()) && track.AlbumArtist() != canonical {
+		return []Finding{{
+			Field:    "album_artist",
+			Value:    track.AlbumArtist(),
+			Expected: canonical,
+			Message:  fmt.Sprintf("Album artist name should be %s (%s).", canonical, track.AlbumArtist()),
+		}}
 	}
 
-	return false, ""
+	return nil
 }
 
-func missingTrackTagRule(path string, track tag.Metadata) (bool, string) {
+func missingTrackTagRule(track *TrackFile, options RuleOptions) []Finding {
 	trackName := sanitizeString(track.Title())
 	if "" == trackName {
-		return true, "Track name is empty."
+		return []Finding{{Field: "title", Value: track.Title(), Message: "Track name is empty."}}
 	}
 
-	return false, ""
+	return nil
 }
 
-func unknowTrackTagRule(path string, track tag.Metadata) (bool, string) {
-	trackName := sanitizeString(track.Title())
-	if true == isUnknow(trackName) {
-		return true, fmt.Sprintf("Track name should be untitled (%s).", track.Title())
-	}
-
-	return false, ""
-}
-
-func missingAlbumTagRule(path string, track tag.Metadata) (bool, string) {
+func missingAlbumTagRule(track *TrackFile, options RuleOptions) []Finding {
 	albumName := sanitizeString(track.Album())
 	if "" == albumName {
-		return true, "Album name is empty."
-	}
-
-	return false, ""
-}
-
-func unknowAlbumTagRule(path string, track tag.Metadata) (bool, string) {
-	albumName := sanitizeString(track.Album())
-	if true == isUnknow(albumName) {
-		return true, fmt.Sprintf("Album name should be untitled (%s).", track.Album())
-	}
-
-	return false, ""
-}
-
-func isUnknow(s string) bool {
-	s = sanitizeString(s)
-	if true == strings.Contains(s, "unknow") {
-		return true
-
-	}
-
-	if true == strings.Contains(s, "untitled") {
-		return true
-	}
-
-	if true == strings.Contains(s, "track") {
-		return true
-	}
-
-	return false
-}
-
-func isVariousArtists(s string) bool {
-	variousArtistsNames := []string{
-		"Various Artists", "Artistiaid Amrywiol", "Amrywiol", "Danske Kunstnere",
-		"Div. kunstnere", "Diverse danske artister", "Diverse kunstnere", "Diverse",
-		"Diverse Interpreten", "Diversen", "versch. Künstler", "Verschiedene",
-		"Verschiedene Interpreten", "Διάφοροι Καλλιτέχνες", "(various)",
-		"[Various Artists]", "Assorted Artists", "Assorted Christian Artists",
-		"Hairspray (Karaoke) Various Artists", "Miscellaneous", "Mixed Artists",
-		"More artists", "Multiple Artists", "Sampler", "Time Life Music: Various Artists",
-		"V. A.", "V. Artist", "V.A.", "V/a", "VA", "Varied", "Various",
-		"Various (not original artists)", "Various (original artists)",
-		"Various Artist - GoGo Wonderful", "Various Big Bands",
-		"Various Celtic Artists", "Various Composers", "Various DJ's", "Various Items",
-		"Various Military Bands", "Various Speakers", "Various Talented Artists", "Compilaciones",
-		"Varios", "Varios artistas", "Erinevad", "Erinevad esitajad", "Eri esittäjiä",
-		"Artistes divers", "Artistes variés", "Bande originale", "Collectif", "Collégiale",
-		"Comp.", "Compilation", "div.", "Divers", "Multi-artistes",
-		"Multi-interprètes", "Variées", "Variés", "A.A.V.V.", "AA.VV.", "AAVV", "Artisti Vari",
-		"ヴァリアス", "ヴァリアス・アーティスト", "オムニバス", "さまざまなアーティスト", "여러 아티스트",
-		"Diverse Artister", "Diverse Artiesten", "Diverse componisten", "Iedereen",
-		"Diverse Artistar", "Różni", "Różni artyści", "Różni wykonawcy", "Wszyscy artysci",
-		"Coletânea", "Vários", "Vários artistas", "Vários intérpretes", "razlichnye ispolniteli",
-		"[различные исполнители]", "Различные исполнители", "различных исполнителей",
-		"Разные артисты", "Razlièni izvajalci", "Blandade artister", "Blandat", "รวมศิลปิน",
-		"หลากหลายศิลปิน", "Çeşitli sanatçılar", "Rizni vykonavci", "Rizni vykonavtsi",
-		"різних виконавців", "Різні виконавці", "Hợp ca", "Nhiều ca sĩ", "Nhiều nghệ sĩ",
-		"Tốp ca", "Tốp ca nam", "群星", "合輯", "Various 80's", "Artis JK", "Artistas Varios",
-		"Artisti uniti per l'Emilia", "Assorted Artisits", "Concatenation Records", "Die Brandstifter",
-		"Div. artister", "DMT[REC]", "Dominicanos Varios", "La Historia de la Fania",
-		"Intérpretes Diversos", "MDB", "Multi Interprètes", "Multi‐interprètes", "Noevir", "OST",
-		"Stockfisch", "Tabu Recz", "Ｖ．Ａ.", "VA", "val", "VAR", "Vari", "Vari Artisti", "varias",
-		"Varias Artistas", "Varies", "Variois", "Varios artists", "Varios Intérpretes",
-		"Various Arists", "various aritsts", "Various Artist", "various artiste",
-		"Various Artistes", "Various Artists [DIY]", "華納群星", "Various Artitsts",
-		"Various Artsits", "Various Compiled By", "Various DHM Artists", "VariousArtist",
-		"Variuos", "Variuos Artists", "Varius", "Varius Artist", "Varius Artists", "Varoius",
-		"Varoius Artists", "Verious", "Vrious Artists", "VV AA", "ぱにぽにだっしゅ！", "世界小姐",
-		"原声带", "多位艺术家", "多位艺术家", "多位藝術家", "影视原声", "歌手",
-	}
-
-	for _, n := range variousArtistsNames {
-		if s == sanitizeString(n) {
-			return true
-		}
+		return []Finding{{Field: "album", Value: track.Album(), Message: "Album name is empty."}}
 	}
 
-	return false
+	return nil
 }
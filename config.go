@@ -0,0 +1,470 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	yaml "gopkg.in/yaml.v2"
+)
+
+var configFileNames = []string{".audiolibtools.yaml", ".audiolibtools.yml"}
+
+//Config is read from .audiolibtools.yaml
+type Config struct {
+	Rules          map[string]RuleConfig `yaml:"rules"`
+	Export         ExportConfig          `yaml:"export"`
+	Cache          string                `yaml:"cache"`
+	VariousArtists VariousArtistsConfig  `yaml:"various_artists"`
+
+	//variousArtists is the dictionary extended or tuned by VariousArtists, nil when unchanged
+	variousArtists *variousArtistsDictionary
+}
+
+//RuleConfig enables, sets the severity and the options of one rule
+type RuleConfig struct {
+	Enabled  *bool       `yaml:"enabled"`
+	Severity string      `yaml:"severity"`
+	Options  RuleOptions `yaml:"options"`
+}
+
+//ExportConfig holds the export command defaults
+type ExportConfig struct {
+	Covers     bool   `yaml:"covers"`
+	CoversPath string `yaml:"covers_path"`
+}
+
+//VariousArtistsConfig tunes the Various Artists dictionary shared by rules
+type VariousArtistsConfig struct {
+	//File holds names extending the dictionary, relative to the configuration file
+	File string `yaml:"file"`
+	//MaxDistance is the edit distance allowed to match a name, 0 to match exactly
+	MaxDistance *int `yaml:"max_distance"`
+}
+
+//findConfig looks for a configuration file in root and its parents
+func findConfig(root string) string {
+	dir, err := filepath.Abs(root)
+	if err != nil {
+		return ""
+	}
+
+	for {
+		for _, name := range configFileNames {
+			path := filepath.Join(dir, name)
+			if info, err := os.Stat(path); err == nil && info.IsDir() == false {
+				return path
+			}
+		}
+
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+//loadConfig reads the configuration file at path, or the one found from root when path is empty
+func loadConfig(path string, root string) (*Config, error) {
+	cfg := &Config{}
+
+	if path == "" {
+		path = findConfig(root)
+	}
+
+	if path == "" {
+		return cfg, nil
+	}
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := yaml.UnmarshalStrict(data, cfg); err != nil {
+		return nil, fmt.Errorf("%s: %v", path, err)
+	}
+
+	if cfg.VariousArtists.File != "" || cfg.VariousArtists.MaxDistance != nil {
+		cfg.variousArtists = newVariousArtistsDictionary()
+	}
+
+	if cfg.VariousArtists.File != "" {
+		namesPath := cfg.VariousArtists.File
+		if filepath.IsAbs(namesPath) == false {
+			namesPath = filepath.Join(filepath.Dir(path), namesPath)
+		}
+
+		if err := cfg.variousArtists.load(namesPath); err != nil {
+			return nil, fmt.Errorf("%s: various_artists: %v", path, err)
+		}
+	}
+
+	if cfg.VariousArtists.MaxDistance != nil {
+		cfg.variousArtists.maxDistance = *cfg.VariousArtists.MaxDistance
+	}
+
+	return cfg, nil
+}
+
+//variousArtistsDictionary returns the Various Artists dictionary of the configuration
+func (cfg *Config) variousArtistsDictionary() *variousArtistsDictionary {
+	if cfg.variousArtists == nil {
+		return defaultVariousArtists
+	}
+
+	return cfg.variousArtists
+}
+
+//configuredTrackRule overrides the severity of a track rule
+type configuredTrackRule struct {
+	TrackRule
+	severity Severity
+}
+
+func (r configuredTrackRule) Severity() Severity { return r.severity }
+
+//configuredAlbumRule overrides the severity of an album rule
+type configuredAlbumRule struct {
+	AlbumRule
+	severity Severity
+}
+
+func (r configuredAlbumRule) Severity() Severity { return r.severity }
+
+//configuredLibraryRule overrides the severity of a library rule
+type configuredLibraryRule struct {
+	LibraryRule
+	severity Severity
+}
+
+func (r configuredLibraryRule) Severity() Severity { return r.severity }
+
+//ruleConfig returns the enabled state and severity of a rule, and a copy of the rule
+//configured with its options; the registered rule is returned when it has no options
+func (cfg *Config) ruleConfig(rule interface{}, id string, severity Severity) (interface{}, bool, Severity, error) {
+	rc, exists := cfg.Rules[id]
+	if exists == false {
+		return rule, true, severity, nil
+	}
+
+	if rc.Enabled != nil && *rc.Enabled == false {
+		return rule, false, severity, nil
+	}
+
+	if rc.Severity != "" {
+		s, err := parseSeverity(rc.Severity)
+		if err != nil {
+			return rule, false, severity, fmt.Errorf("rule %s: %v", id, err)
+		}
+		severity = s
+	}
+
+	if rc.Options != nil {
+		configurable, ok := rule.(Configurable)
+		if ok == false {
+			return rule, false, severity, fmt.Errorf("rule %s has no options", id)
+		}
+
+		configured, err := configurable.Configure(rc.Options)
+		if err != nil {
+			return rule, false, severity, fmt.Errorf("rule %s: %v", id, err)
+		}
+		rule = configured
+	}
+
+	return rule, true, severity, nil
+}
+
+//trackRules returns the enabled track rules with their configured severity
+func (cfg *Config) trackRules() ([]TrackRule, error) {
+	var rules []TrackRule
+	var errs []string
+	for _, registered := range TrackRules() {
+		configured, enabled, severity, err := cfg.ruleConfig(registered, registered.ID(), registered.Severity())
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		if enabled == false {
+			continue
+		}
+
+		rule := configured.(TrackRule)
+
+		if severity != rule.Severity() {
+			rule = configuredTrackRule{rule, severity}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	return rules, nil
+}
+
+//albumRules returns the enabled album rules with their configured severity
+func (cfg *Config) albumRules() ([]AlbumRule, error) {
+	var rules []AlbumRule
+	var errs []string
+	for _, registered := range AlbumRules() {
+		configured, enabled, severity, err := cfg.ruleConfig(registered, registered.ID(), registered.Severity())
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		if enabled == false {
+			continue
+		}
+
+		rule := configured.(AlbumRule)
+
+		if severity != rule.Severity() {
+			rule = configuredAlbumRule{rule, severity}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	return rules, nil
+}
+
+//libraryRules returns the enabled library rules with their configured severity
+func (cfg *Config) libraryRules() ([]LibraryRule, error) {
+	var rules []LibraryRule
+	var errs []string
+	for _, registered := range LibraryRules() {
+		configured, enabled, severity, err := cfg.ruleConfig(registered, registered.ID(), registered.Severity())
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+
+		if enabled == false {
+			continue
+		}
+
+		rule := configured.(LibraryRule)
+
+		if severity != rule.Severity() {
+			rule = configuredLibraryRule{rule, severity}
+		}
+
+		rules = append(rules, rule)
+	}
+
+	if len(errs) > 0 {
+		return nil, errors.New(strings.Join(errs, "\n"))
+	}
+
+	return rules, nil
+}
+
+//validate reports rule IDs that do not match any registered rule
+func (cfg *Config) validate() error {
+	for id := range cfg.Rules {
+		if findRule(id) == false {
+			return fmt.Errorf("unknown rule %q in configuration", id)
+		}
+	}
+
+	return nil
+}
+
+func findRule(id string) bool {
+	for _, rule := range TrackRules() {
+		if rule.ID() == id {
+			return true
+		}
+	}
+
+	for _, rule := range AlbumRules() {
+		if rule.ID() == id {
+			return true
+		}
+	}
+
+	for _, rule := range LibraryRules() {
+		if rule.ID() == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+//RuleOptions holds the options of a rule from the configuration file.
+//Getters return the default when an option is unset and an error when it has another type.
+type RuleOptions map[string]interface{}
+
+//String returns a string option or def when unset
+func (o RuleOptions) String(key string, def string) (string, error) {
+	value, exists := o[key]
+	if exists == false {
+		return def, nil
+	}
+
+	s, ok := value.(string)
+	if ok == false {
+		return def, optionTypeError(key, "a string", value)
+	}
+
+	return s, nil
+}
+
+//Int returns an integer option or def when unset
+func (o RuleOptions) Int(key string, def int) (int, error) {
+	value, exists := o[key]
+	if exists == false {
+		return def, nil
+	}
+
+	i, ok := value.(int)
+	if ok == false {
+		return def, optionTypeError(key, "an integer", value)
+	}
+
+	return i, nil
+}
+
+//Bool returns a boolean option or def when unset
+func (o RuleOptions) Bool(key string, def bool) (bool, error) {
+	value, exists := o[key]
+	if exists == false {
+		return def, nil
+	}
+
+	b, ok := value.(bool)
+	if ok == false {
+		return def, optionTypeError(key, "true or false", value)
+	}
+
+	return b, nil
+}
+
+//Strings returns a list option or def when unset
+func (o RuleOptions) Strings(key string, def []string) ([]string, error) {
+	value, exists := o[key]
+	if exists == false {
+		return def, nil
+	}
+
+	list, ok := value.([]interface{})
+	if ok == false {
+		return def, optionTypeError(key, "a list", value)
+	}
+
+	values := []string{}
+	for _, v := range list {
+		switch v.(type) {
+		case []interface{}, map[interface{}]interface{}, nil:
+			return def, optionTypeError(key, "a list of strings", value)
+		}
+		values = append(values, fmt.Sprint(v))
+	}
+
+	return values, nil
+}
+
+//StringMap returns a mapping option or def when unset
+func (o RuleOptions) StringMap(key string, def map[string]string) (map[string]string, error) {
+	value, exists := o[key]
+	if exists == false {
+		return def, nil
+	}
+
+	m, ok := value.(map[interface{}]interface{})
+	if ok == false {
+		return def, optionTypeError(key, "a mapping", value)
+	}
+
+	values := map[string]string{}
+	for k, v := range m {
+		switch v.(type) {
+		case []interface{}, map[interface{}]interface{}, nil:
+			return def, optionTypeError(key, "a mapping of strings", value)
+		}
+		values[fmt.Sprint(k)] = fmt.Sprint(v)
+	}
+
+	return values, nil
+}
+
+func optionTypeError(key string, expected string, value interface{}) error {
+	return fmt.Errorf("option %s must be %s, got %#v", key, expected, value)
+}
+
+//checkKeys reports options that are not among keys, such as misspelled ones
+func (o RuleOptions) checkKeys(keys ...string) error {
+	var unknown []string
+	for key := range o {
+		if containsString(keys, key) == false {
+			unknown = append(unknown, key)
+		}
+	}
+
+	if len(unknown) == 0 {
+		return nil
+	}
+	sort.Strings(unknown)
+
+	if len(keys) == 0 {
+		return fmt.Errorf("unknown options %s, the rule has no options", strings.Join(unknown, ", "))
+	}
+
+	return fmt.Errorf("unknown options %s, expected %s", strings.Join(unknown, ", "), strings.Join(keys, ", "))
+}
+
+//checkDefaults checks options against the options a rule declares: keys must be declared
+//and values must have the type of the declared default
+func (o RuleOptions) checkDefaults(defaults RuleOptions) error {
+	var keys []string
+	for key := range defaults {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	if err := o.checkKeys(keys...); err != nil {
+		return err
+	}
+
+	var errs []string
+	for _, key := range keys {
+		var err error
+		switch def := defaults[key].(type) {
+		case string:
+			_, err = o.String(key, def)
+		case int:
+			_, err = o.Int(key, def)
+		case bool:
+			_, err = o.Bool(key, def)
+		case []string:
+			_, err = o.Strings(key, def)
+		case map[string]string:
+			_, err = o.StringMap(key, def)
+		}
+		if err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return errors.New(strings.Join(errs, "; "))
+	}
+
+	return nil
+}
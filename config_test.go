@@ -0,0 +1,179 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestRuleOptionsGetters(t *testing.T) {
+	options := RuleOptions{
+		"name":    "value",
+		"count":   3,
+		"enabled": true,
+		"list":    []interface{}{"a", 1},
+		"map":     map[interface{}]interface{}{"a": "b", 1: 2},
+		"nested":  []interface{}{[]interface{}{"a"}},
+	}
+
+	if s, err := options.String("name", "def"); s != "value" || err != nil {
+		t.Errorf("String = %q, %v", s, err)
+	}
+	if s, err := options.String("unset", "def"); s != "def" || err != nil {
+		t.Errorf("String unset = %q, %v", s, err)
+	}
+	if i, err := options.Int("count", 1); i != 3 || err != nil {
+		t.Errorf("Int = %d, %v", i, err)
+	}
+	if b, err := options.Bool("enabled", false); b != true || err != nil {
+		t.Errorf("Bool = %v, %v", b, err)
+	}
+	if l, err := options.Strings("list", nil); reflect.DeepEqual(l, []string{"a", "1"}) == false || err != nil {
+		t.Errorf("Strings = %v, %v", l, err)
+	}
+	if m, err := options.StringMap("map", nil); reflect.DeepEqual(m, map[string]string{"a": "b", "1": "2"}) == false || err != nil {
+		t.Errorf("StringMap = %v, %v", m, err)
+	}
+
+	//wrong types are errors, not silent defaults
+	if _, err := options.Int("name", 1); err == nil {
+		t.Errorf("Int of a string: expected an error")
+	}
+	if _, err := options.String("count", ""); err == nil {
+		t.Errorf("String of an integer: expected an error")
+	}
+	if _, err := options.Bool("name", false); err == nil {
+		t.Errorf("Bool of a string: expected an error")
+	}
+	if _, err := options.Strings("name", nil); err == nil {
+		t.Errorf("Strings of a string: expected an error")
+	}
+	if _, err := options.Strings("nested", nil); err == nil {
+		t.Errorf("Strings of nested lists: expected an error")
+	}
+	if _, err := options.StringMap("list", nil); err == nil {
+		t.Errorf("StringMap of a list: expected an error")
+	}
+}
+
+func TestRuleOptionsCheckDefaults(t *testing.T) {
+	defaults := RuleOptions{"min_year": 1877, "fields": []string{"artist"}, "aliases": map[string]string{}}
+
+	tests := []struct {
+		options RuleOptions
+		valid   bool
+	}{
+		{RuleOptions{}, true},
+		{RuleOptions{"min_year": 1900, "fields": []interface{}{"album"}}, true},
+		{RuleOptions{"aliases": map[interface{}]interface{}{"Hip Hop": "Hip-Hop"}}, true},
+		{RuleOptions{"min_year": "1900"}, false},
+		{RuleOptions{"fields": "album"}, false},
+		{RuleOptions{"min_yaer": 1900}, false},
+	}
+
+	for _, test := range tests {
+		if err := test.options.checkDefaults(defaults); (err == nil) != test.valid {
+			t.Errorf("%v: got error %v, want valid %v", test.options, err, test.valid)
+		}
+	}
+
+	if err := (RuleOptions{"foo": 1}).checkDefaults(nil); err == nil {
+		t.Errorf("options of a rule without options: expected an error")
+	}
+}
+
+func writeConfig(t *testing.T, content string) string {
+	dir, err := ioutil.TempDir("", "audiolibtools")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := filepath.Join(dir, configFileNames[0])
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestConfigRulesErrors(t *testing.T) {
+	path := writeConfig(t, `
+rules:
+  invalid-year:
+    options:
+      min_year: "1900"
+  unknown-title:
+    options:
+      langauges: [en]
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	cfg, err := loadConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = cfg.trackRules()
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	for _, want := range []string{"invalid-year", "unknown-title"} {
+		if strings.Contains(err.Error(), want) == false {
+			t.Errorf("error %q does not report %s", err, want)
+		}
+	}
+}
+
+//TestConfigDoesNotLeak checks configurations leave the registered rules and the built-in dictionaries as they are
+func TestConfigDoesNotLeak(t *testing.T) {
+	path := writeConfig(t, `
+various_artists:
+  max_distance: 0
+rules:
+  unknown-title:
+    options:
+      languages: []
+  title-case:
+    options:
+      style: title
+`)
+	defer os.RemoveAll(filepath.Dir(path))
+
+	cfg, err := loadConfig(path, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	configured, err := cfg.trackRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	matchers := suspiciousMatchers(configured)
+	if matchers["title"].match("Track 01") != "" {
+		t.Errorf("configured unknown-title matcher still holds the built-in patterns")
+	}
+
+	for _, rule := range TrackRules() {
+		switch r := rule.(type) {
+		case *suspiciousRule:
+			if r.matcher.match("Track 01") == "" {
+				t.Errorf("registered %s rule lost its built-in patterns", r.id)
+			}
+		case *caseRule:
+			if r.style != styleAsIs {
+				t.Errorf("registered %s rule style is %s", r.id, r.style)
+			}
+		}
+	}
+
+	if cfg.variousArtistsDictionary().maxDistance != 0 {
+		t.Errorf("configured Various Artists distance is %d", cfg.variousArtistsDictionary().maxDistance)
+	}
+	if defaultVariousArtists.maxDistance != defaultVariousArtistsDistance {
+		t.Errorf("built-in Various Artists distance changed to %d", defaultVariousArtists.maxDistance)
+	}
+}
@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"image"
+	//decoders registered for image.Decode and image.DecodeConfig
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"net/http"
+	"strings"
+	"sync"
+
+	tag "github.com/dhowden/tag"
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/webp"
+)
+
+//coverInfo describes the embedded picture of an audio file, as found by decoding its data
+type coverInfo struct {
+	//DetectedType is the MIME type sniffed from the image bytes
+	DetectedType string `json:"detected_type"`
+	Width        int    `json:"width"`
+	Height       int    `json:"height"`
+	Size         int    `json:"size"`
+	DecodeError  string `json:"decode_error,omitempty"`
+}
+
+//coverMemoSize bounds the memo of decoded pictures. Tracks of an album are read one
+//after the other, so the pictures worth remembering are the recent ones.
+const coverMemoSize = 64
+
+//coverMemo remembers the last decoded pictures by content hash, as every track of an album
+//usually embeds the same picture
+type coverMemo struct {
+	mu    sync.Mutex
+	infos map[[sha1.Size]byte]*coverInfo
+	//keys holds the hashes in insertion order, the oldest is evicted first
+	keys [][sha1.Size]byte
+}
+
+func (m *coverMemo) load(key [sha1.Size]byte) (*coverInfo, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	info, exists := m.infos[key]
+
+	return info, exists
+}
+
+func (m *coverMemo) store(key [sha1.Size]byte, info *coverInfo) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.infos == nil {
+		m.infos = map[[sha1.Size]byte]*coverInfo{}
+	}
+	if _, exists := m.infos[key]; exists == true {
+		return
+	}
+
+	if len(m.keys) >= coverMemoSize {
+		delete(m.infos, m.keys[0])
+		m.keys = m.keys[1:]
+	}
+	m.infos[key] = info
+	m.keys = append(m.keys, key)
+}
+
+var decodedCovers = &coverMemo{}
+
+//decodeCover reads the type and dimensions of a picture from its header, then decodes
+//the whole picture to check its data; the decoded image is not kept
+func decodeCover(p *tag.Picture) *coverInfo {
+	if p == nil {
+		return nil
+	}
+
+	key := sha1.Sum(p.Data)
+	if info, exists := decodedCovers.load(key); exists == true {
+		return info
+	}
+
+	info := &coverInfo{Size: len(p.Data)}
+	if len(p.Data) > 0 {
+		info.DetectedType = http.DetectContentType(p.Data)
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(p.Data))
+	if err == nil {
+		info.Width, info.Height = config.Width, config.Height
+		_, _, err = image.Decode(bytes.NewReader(p.Data))
+	}
+	if err != nil {
+		info.DecodeError = err.Error()
+	}
+
+	decodedCovers.store(key, info)
+
+	return info
+}
+
+//sameImageType tells if a declared MIME type or file extension names the detected MIME type
+func sameImageType(declared string, detected string) bool {
+	declared = strings.TrimPrefix(sanitizeString(declared), ".")
+	detected = strings.TrimPrefix(sanitizeString(detected), "image/")
+
+	switch declared {
+	case "jpg", "jpeg", "image/jpg", "image/jpeg", "image/pjpeg":
+		return detected == "jpeg"
+	case "png", "image/png":
+		return detected == "png"
+	case "gif", "image/gif":
+		return detected == "gif"
+	case "bmp", "image/bmp":
+		return detected == "bmp"
+	case "webp", "image/webp":
+		return detected == "webp"
+	}
+
+	return false
+}
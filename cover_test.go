@@ -0,0 +1,59 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"image"
+	"image/png"
+	"testing"
+
+	tag "github.com/dhowden/tag"
+)
+
+func pngData(t *testing.T, width int, height int) []byte {
+	var b bytes.Buffer
+	if err := png.Encode(&b, image.NewGray(image.Rect(0, 0, width, height))); err != nil {
+		t.Fatal(err)
+	}
+
+	return b.Bytes()
+}
+
+func TestDecodeCover(t *testing.T) {
+	data := pngData(t, 600, 400)
+
+	info := decodeCover(&tag.Picture{Data: data})
+	if info.Width != 600 || info.Height != 400 || info.DetectedType != "image/png" || info.DecodeError != "" {
+		t.Errorf("got %+v", info)
+	}
+
+	//a truncated picture has a readable header but does not decode
+	info = decodeCover(&tag.Picture{Data: data[:len(data)-20]})
+	if info.DecodeError == "" {
+		t.Errorf("truncated picture: got %+v, want a decode error", info)
+	}
+
+	info = decodeCover(&tag.Picture{Data: []byte("not a picture")})
+	if info.DecodeError == "" {
+		t.Errorf("text: got %+v, want a decode error", info)
+	}
+}
+
+func TestCoverMemoBound(t *testing.T) {
+	memo := &coverMemo{}
+	for i := 0; i < coverMemoSize*3; i++ {
+		memo.store(sha1.Sum([]byte{byte(i), byte(i >> 8)}), &coverInfo{Size: i})
+	}
+
+	if len(memo.infos) != coverMemoSize || len(memo.keys) != coverMemoSize {
+		t.Errorf("memo holds %d pictures, want %d", len(memo.infos), coverMemoSize)
+	}
+
+	last := coverMemoSize*3 - 1
+	if info, exists := memo.load(sha1.Sum([]byte{byte(last), byte(last >> 8)})); exists == false || info.Size != last {
+		t.Errorf("last picture is not remembered")
+	}
+	if _, exists := memo.load(sha1.Sum([]byte{0, 0})); exists == true {
+		t.Errorf("first picture is still remembered")
+	}
+}
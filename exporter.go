@@ -10,46 +10,31 @@ import (
 	uuid "github.com/satori/go.uuid"
 )
 
-func extract(root string) []TrackFlat {
-	var tracks []string
-
+func extract(root string, jobs int, cache *scanCache) ([]TrackFlat, error) {
 	t := []TrackFlat{}
 
-	filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
-		if info.IsDir() == true {
-			return nil
-		}
-
-		if isAudioFile(filepath.Ext(path)) == false {
-			return nil
-		}
-
-		tracks = append(tracks, path)
-
-		return nil
-	})
-
-	for _, trackPath := range tracks {
-
-		m, err := getTrackMetaData(trackPath)
-		if err != nil {
-			fmt.Printf("error reading file: %v\n", err)
+	lib, err := scanLibrary(root, jobs, cache)
+	if err != nil {
+		return nil, err
+	}
 
-			continue
-		}
+	for _, m := range lib.Tracks {
 
-		mbTags := mbz.Extract(m)
-		track, _ := m.Track()
-		disc, _ := m.Disc()
-		trackAbsPath, _ := filepath.Abs(trackPath)
+		mbTags := m.MusicBrainz()
+		track, trackTotal := m.Track()
+		disc, discTotal := m.Disc()
+		trackAbsPath, _ := filepath.Abs(m.Path)
 		oTrack := TrackFlat{
 			Track:             track,
+			TrackTotal:        trackTotal,
 			Disc:              disc,
+			DiscTotal:         discTotal,
 			Title:             m.Title(),
 			Album:             m.Album(),
 			Artist:            m.Artist(),
 			AlbumArtist:       m.AlbumArtist(),
 			Year:              m.Year(),
+			ReleaseDate:       releaseDate(m),
 			Path:              trackAbsPath,
 			MbTrackUUID:       mbTags.Get(mbz.Track),
 			MbTrackArtistUUID: mbTags.Get(mbz.Artist),
@@ -60,7 +45,7 @@ func extract(root string) []TrackFlat {
 		t = append(t, oTrack)
 	}
 
-	return t
+	return t, nil
 }
 
 func copyAlbumCover(album Album, dir string) string {
@@ -93,6 +78,7 @@ func copyAlbumCover(album Album, dir string) string {
 
 func transform(tf []TrackFlat, exportAlbumCover bool, exportAlbumCoverDir string) []Album {
 	mAlbums := map[string]*Album{}
+	var albumsOrder []*Album
 	mArtists := map[string]*Artist{}
 	for _, trackFlat := range tf {
 
@@ -130,21 +116,31 @@ func transform(tf []TrackFlat, exportAlbumCover bool, exportAlbumCoverDir string
 		_, exists = mAlbums[slugAlbum]
 		if exists == true {
 			album, _ = mAlbums[slugAlbum]
+			if album.Year == 0 {
+				album.Year = trackFlat.Year
+			}
+			if len(trackFlat.ReleaseDate) > len(album.ReleaseDate) {
+				album.ReleaseDate = trackFlat.ReleaseDate
+			}
 		} else {
 			album = &Album{
 				Name:              trackFlat.Album,
 				Year:              trackFlat.Year,
+				ReleaseDate:       trackFlat.ReleaseDate,
 				MbAlbumArtistUUID: trackFlat.MbAlbumArtistUUID,
 				MbAlbumUUID:       trackFlat.MbAblumUUID,
 				AlbumArtist:       *artistAlbum,
 			}
 
 			mAlbums[slugAlbum] = album
+			albumsOrder = append(albumsOrder, album)
 		}
 
 		track = &Track{
 			Track:        trackFlat.Track,
+			TrackTotal:   trackFlat.TrackTotal,
 			Disc:         trackFlat.Disc,
+			DiscTotal:    trackFlat.DiscTotal,
 			Title:        trackFlat.Title,
 			Album:        *album,
 			Artist:       *artist,
@@ -164,7 +160,7 @@ func transform(tf []TrackFlat, exportAlbumCover bool, exportAlbumCoverDir string
 	}
 
 	albums := []Album{}
-	for _, a := range mAlbums {
+	for _, a := range albumsOrder {
 
 		if exportAlbumCover {
 			a.CoverPath = copyAlbumCover(*a, exportAlbumCoverDir)
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+const ignoreFileName = ".audiolibignore"
+
+//ignoreEntry suppresses rules for paths matching a glob relative to the ignore file directory
+type ignoreEntry struct {
+	base    string
+	pattern string
+	rules   []string
+}
+
+//ignoreList holds the entries of every ignore file found under the checked root
+type ignoreList struct {
+	entries []ignoreEntry
+}
+
+//readIgnoreFile parses an ignore file. Each line holds a glob and optional rule IDs:
+//
+//	# comment
+//	Bootlegs/*                 unknown-title unknown-album
+//	Compilations/Various*
+//	.                          suspicious-various-artists
+func readIgnoreFile(path string) ([]ignoreEntry, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var entries []ignoreEntry
+	var lineNumber = 0
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		lineNumber++
+
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.Fields(strings.Replace(line, ",", " ", -1))
+		pattern := filepath.Clean(filepath.FromSlash(fields[0]))
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s:%d: %v", path, lineNumber, err)
+		}
+
+		for _, id := range fields[1:] {
+			if findRule(id) == false {
+				return nil, fmt.Errorf("%s:%d: unknown rule %q", path, lineNumber, id)
+			}
+		}
+
+		entries = append(entries, ignoreEntry{
+			base:    filepath.Dir(path),
+			pattern: pattern,
+			rules:   fields[1:],
+		})
+	}
+
+	return entries, scanner.Err()
+}
+
+func (l *ignoreList) add(path string) error {
+	entries, err := readIgnoreFile(path)
+	if err != nil {
+		return err
+	}
+
+	l.entries = append(l.entries, entries...)
+
+	return nil
+}
+
+//suppressed tells if a finding is silenced by an ignore file
+func (l *ignoreList) suppressed(f Finding) bool {
+	for _, e := range l.entries {
+		if e.matches(f) == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (e ignoreEntry) matches(f Finding) bool {
+	if len(e.rules) > 0 && containsString(e.rules, f.RuleID) == false {
+		return false
+	}
+
+	rel, err := filepath.Rel(e.base, f.Path)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return false
+	}
+
+	if e.pattern == "." {
+		return true
+	}
+
+	//match the path or any of its parent directories
+	for rel != "." && rel != string(filepath.Separator) {
+		if matched, _ := filepath.Match(e.pattern, rel); matched == true {
+			return true
+		}
+		rel = filepath.Dir(rel)
+	}
+
+	return false
+}
+
+//filter splits findings into reported and suppressed ones
+func (l *ignoreList) filter(findings []Finding) ([]Finding, int) {
+	if len(l.entries) == 0 {
+		return findings, 0
+	}
+
+	var reported []Finding
+	var suppressed = 0
+	for _, f := range findings {
+		if l.suppressed(f) == true {
+			suppressed++
+			continue
+		}
+
+		reported = append(reported, f)
+	}
+
+	return reported, suppressed
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+//junitSection holds the results of one check section until the report is written
+type junitSection struct {
+	name  string
+	rules []RuleInfo
+	//checked counts the albums or tracks of the section, 0 when the section only reports paths with findings
+	checked int
+	//failures by rule ID, paths in report order
+	failures map[string][]junitPathFailure
+}
+
+//junitPathFailure holds the findings of one rule on one path
+type junitPathFailure struct {
+	path     string
+	findings []Finding
+}
+
+//junitReporter writes one test suite per rule, holding one test case per failing path
+//and a single test case for all passing paths: a case per checked path is too much for CI on large libraries
+type junitReporter struct {
+	w        io.Writer
+	root     string
+	sections []*junitSection
+}
+
+func (r *junitReporter) StartSection(section string, rules []RuleInfo) {
+	r.sections = append(r.sections, &junitSection{
+		name:     section,
+		rules:    rules,
+		failures: map[string][]junitPathFailure{},
+	})
+}
+
+func (r *junitReporter) Report(section string, path string, findings []Finding) {
+	s := r.sections[len(r.sections)-1]
+
+	for _, f := range findings {
+		failures := s.failures[f.RuleID]
+		if len(failures) == 0 || failures[len(failures)-1].path != path {
+			failures = append(failures, junitPathFailure{path: path})
+		}
+		failures[len(failures)-1].findings = append(failures[len(failures)-1].findings, f)
+		s.failures[f.RuleID] = failures
+	}
+}
+
+func (r *junitReporter) LimitReached(section string) {}
+
+func (r *junitReporter) EndSection(section string, summary SectionSummary) {
+	//library rules check the library as a whole, passing paths are unknown
+	if section != sectionLibrary {
+		r.sections[len(r.sections)-1].checked = summary.Checked
+	}
+}
+
+func (r *junitReporter) Finish(summary Summary) error {
+	suites := junitTestSuites{Name: "audio-lib-tools"}
+
+	for _, s := range r.sections {
+		for _, rule := range s.rules {
+			suite := junitTestSuite{Name: s.name + "." + rule.ID}
+
+			for _, failure := range s.failures[rule.ID] {
+				var messages []string
+				for _, f := range failure.findings {
+					messages = append(messages, f.Message)
+				}
+
+				suite.Cases = append(suite.Cases, junitTestCase{
+					ClassName: s.name + "." + rule.ID,
+					Name:      relativeURI(r.root, failure.path),
+					Failure: &junitFailure{
+						Message: failure.findings[0].Message,
+						Type:    failure.findings[0].Severity.String(),
+						Text:    strings.Join(messages, "\n"),
+					},
+				})
+				suite.Failures++
+			}
+
+			if passed := s.checked - suite.Failures; passed > 0 {
+				suite.Cases = append(suite.Cases, junitTestCase{
+					ClassName: s.name + "." + rule.ID,
+					Name:      fmt.Sprintf("%d %s passed", passed, s.name),
+				})
+			}
+			suite.Tests = len(suite.Cases)
+
+			suites.Suites = append(suites.Suites, suite)
+			suites.Tests += suite.Tests
+			suites.Failures += suite.Failures
+		}
+	}
+
+	if _, err := io.WriteString(r.w, xml.Header); err != nil {
+		return err
+	}
+
+	enc := xml.NewEncoder(r.w)
+	enc.Indent("", "  ")
+	if err := enc.Encode(suites); err != nil {
+		return err
+	}
+
+	_, err := io.WriteString(r.w, "\n")
+
+	return err
+}
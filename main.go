@@ -2,10 +2,9 @@ package main
 
 import (
 	"encoding/json"
-	"io/ioutil"
+	"fmt"
 	"log"
 	"os"
-	"path/filepath"
 	"strings"
 
 	tag "github.com/dhowden/tag"
@@ -25,6 +24,7 @@ type Album struct {
 	Tracks            []Track `json:"tracks"`
 	Name              string  `json:"name,omitempty"`
 	Year              int     `json:"year,omitempty"`
+	ReleaseDate       string  `json:"release_date,omitempty"`
 	AlbumArtist       Artist  `json:"album_artist"`
 	CoverPath         string  `json:"cover_path,omitempty"`
 	MbAlbumArtistUUID string  `json:"mb_album_artist_uuid,omitempty"`
@@ -34,7 +34,9 @@ type Album struct {
 //Track struct
 type Track struct {
 	Track        int    `json:"track"`
+	TrackTotal   int    `json:"track_total,omitempty"`
 	Disc         int    `json:"disc,omitempty"`
+	DiscTotal    int    `json:"disc_total,omitempty"`
 	Title        string `json:"title,omitempty"`
 	Album        Album  `json:"-"`
 	Artist       Artist `json:"artist"`
@@ -46,12 +48,15 @@ type Track struct {
 //TrackFlat struct
 type TrackFlat struct {
 	Track             int
+	TrackTotal        int
 	Disc              int
+	DiscTotal         int
 	Title             string
 	Album             string
 	Artist            string
 	AlbumArtist       string
 	Year              int
+	ReleaseDate       string
 	Path              string
 	MbTrackUUID       string
 	MbTrackArtistUUID string
@@ -60,14 +65,20 @@ type TrackFlat struct {
 	MbArtistUUID      string
 }
 
+const version = "0.0.2"
+
 func main() {
 	app := cli.NewApp()
 
-	app.Version = "0.0.2"
+	app.Version = version
+	app.OnUsageError = usageError
 	app.Commands = []cli.Command{
 		{
-			Name:  "check",
-			Usage: "Check your audio library files tags errors",
+			Name:         "check",
+			OnUsageError: usageError,
+			Usage:        "Check your audio library files tags errors",
+			Description: "Exit codes: 0 when no finding reaches --fail-on and no threshold is exceeded, " +
+				"1 when findings reach --fail-on or exceed --max-errors/--max-warnings, 2 on usage or I/O errors.",
 			Flags: []cli.Flag{
 				cli.BoolFlag{
 					Name:  "albums, a",
@@ -77,25 +88,65 @@ func main() {
 					Name:  "tracks, t",
 					Usage: "Check tracks.",
 				},
+				cli.BoolFlag{
+					Name:  "library",
+					Usage: "Check library-wide rules.",
+				},
 				cli.BoolFlag{
 					Name:  "only-errors",
 					Usage: "Show only errors.",
 				},
 				cli.IntFlag{
 					Name:  "limit, l",
-					Usage: "Limit number of errors.",
+					Usage: "Limit number of displayed errors, console format only.",
 					Value: 0,
 				},
+				cli.StringFlag{
+					Name:  "fail-on",
+					Usage: "Exit with code 1 when a finding has this severity or a higher one (error, warning, none).",
+					Value: "error",
+				},
+				cli.IntFlag{
+					Name:  "max-errors",
+					Usage: "Exit with code 1 when there are more errors, whatever --fail-on is (-1 to disable).",
+					Value: -1,
+				},
+				cli.IntFlag{
+					Name:  "max-warnings",
+					Usage: "Exit with code 1 when there are more warnings, whatever --fail-on is (-1 to disable).",
+					Value: -1,
+				},
+				cli.StringFlag{
+					Name:  "format, f",
+					Usage: "Output format (" + strings.Join(reportFormats, ", ") + ").",
+					Value: "console",
+				},
+				cli.IntFlag{
+					Name:  "jobs, j",
+					Usage: "Number of files read in parallel.",
+					Value: defaultJobs,
+				},
+				cli.StringFlag{
+					Name:  "config",
+					Usage: "Configuration file (default: " + configFileNames[0] + " found from the root upwards).",
+				},
+				cacheFlag,
+				cli.BoolFlag{
+					Name:  "no-cache",
+					Usage: "Read every file, without using the scan cache.",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				root := c.Args().Get(0)
 
 				var checkAlbums = true
 				var checkTracks = true
+				var checkLibrary = true
 
-				if c.Bool("albums") == true || c.Bool("tracks") == true {
+				if c.Bool("albums") == true || c.Bool("tracks") == true || c.Bool("library") == true {
 					checkAlbums = false
 					checkTracks = false
+					checkLibrary = false
 				}
 
 				if c.Bool("tracks") == true {
@@ -106,19 +157,72 @@ func main() {
 					checkAlbums = true
 				}
 
+				if c.Bool("library") == true {
+					checkLibrary = true
+				}
+
 				if root == "" {
-					color.Red("A root must be specified.")
-					return nil
+					return cli.NewExitError(color.RedString("A root must be specified."), exitUsage)
 				}
 
-				check(root, checkTracks, checkAlbums, c.Int("limit"), c.Bool("only-errors"))
+				failOn, err := parseFailOn(c.String("fail-on"))
+				if err != nil {
+					return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+				}
+
+				cfg, err := loadConfig(c.String("config"), root)
+				if err == nil {
+					err = cfg.validate()
+				}
+				if err != nil {
+					return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+				}
+
+				cache := openCommandCache(c, cfg)
+				if cache != nil {
+					defer cache.close()
+				}
+
+				reporter, err := newReporter(c.String("format"), os.Stdout, root)
+				if err != nil {
+					return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+				}
+
+				//the limit is for humans, machine readable reports hold every finding
+				limit := c.Int("limit")
+				if _, ok := reporter.(*consoleReporter); ok == false {
+					limit = 0
+				}
+
+				summary, err := check(root, checkOptions{
+					Tracks:     checkTracks,
+					Albums:     checkAlbums,
+					Library:    checkLibrary,
+					Limit:      limit,
+					OnlyErrors: c.Bool("only-errors"),
+					Jobs:       c.Int("jobs"),
+					Config:     cfg,
+					Cache:      cache,
+				}, reporter)
+				if err != nil {
+					return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+				}
+
+				if summary.ReadErrors > 0 {
+					return cli.NewExitError(color.RedString("%d files could not be read", summary.ReadErrors), exitUsage)
+				}
+
+				if checkFailed(summary, failOn, c.Int("max-errors"), c.Int("max-warnings")) == true {
+					return cli.NewExitError("", exitFindings)
+				}
 
 				return nil
 			},
 		},
 		{
-			Name:  "export",
-			Usage: "Export tracks to a json file",
+			Name:         "export",
+			OnUsageError: usageError,
+			Usage:        "Export tracks to a json file",
 			Flags: []cli.Flag{
 				cli.BoolFlag{
 					Name:  "covers, c",
@@ -129,6 +233,20 @@ func main() {
 					Usage: "Extract covers into this directory.",
 					Value: "./covers",
 				},
+				cli.IntFlag{
+					Name:  "jobs, j",
+					Usage: "Number of files read in parallel.",
+					Value: defaultJobs,
+				},
+				cli.StringFlag{
+					Name:  "config",
+					Usage: "Configuration file (default: " + configFileNames[0] + " found from the root upwards).",
+				},
+				cacheFlag,
+				cli.BoolFlag{
+					Name:  "no-cache",
+					Usage: "Read every file, without using the scan cache.",
+				},
 			},
 			Action: func(c *cli.Context) error {
 				root := c.Args().Get(0)
@@ -143,13 +261,35 @@ func main() {
 					return nil
 				}
 
+				cfg, err := loadConfig(c.String("config"), root)
+				if err == nil {
+					err = cfg.validate()
+				}
+				if err != nil {
+					color.Red(err.Error())
+					return nil
+				}
+
 				var exportCoversDirectory = c.String("covers-path")
-				var exportCovers = false
+				if c.IsSet("covers-path") == false && cfg.Export.CoversPath != "" {
+					exportCoversDirectory = cfg.Export.CoversPath
+				}
+
+				var exportCovers = cfg.Export.Covers
 				if c.Bool("covers") == true {
 					exportCovers = true
 				}
 
-				tf := extract(root)
+				cache := openCommandCache(c, cfg)
+				if cache != nil {
+					defer cache.close()
+				}
+
+				tf, err := extract(root, c.Int("jobs"), cache)
+				if err != nil {
+					color.Red(err.Error())
+					return nil
+				}
 
 				albums := transform(tf, exportCovers, exportCoversDirectory)
 
@@ -168,14 +308,90 @@ func main() {
 				return nil
 			},
 		},
+		{
+			Name:         "cache",
+			OnUsageError: usageError,
+			Usage:        "Manage the scan cache",
+			Subcommands: []cli.Command{
+				{
+					Name:         "stats",
+					OnUsageError: usageError,
+					Usage:        "Show scan cache entries",
+					ArgsUsage:    "[root]",
+					Flags:        []cli.Flag{cacheFlag, configFlag},
+					Action: func(c *cli.Context) error {
+						path, err := cacheCommandPath(c)
+						if err != nil {
+							return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+						}
+
+						cache, err := openCache(path)
+						if err != nil {
+							color.Red(err.Error())
+							return nil
+						}
+						defer cache.close()
+
+						stats, err := cache.stats()
+						if err != nil {
+							color.Red(err.Error())
+							return nil
+						}
+
+						color.Green("Cache file: %s", stats.Path)
+						color.Green("Size: %d bytes", stats.Size)
+						color.Green("Entries: %d", stats.Entries)
+						color.Yellow("Stale entries: %d", stats.Stale)
+
+						return nil
+					},
+				},
+				{
+					Name:         "prune",
+					OnUsageError: usageError,
+					Usage:        "Remove entries of removed or changed files",
+					ArgsUsage:    "[root]",
+					Flags:        []cli.Flag{cacheFlag, configFlag},
+					Action: func(c *cli.Context) error {
+						path, err := cacheCommandPath(c)
+						if err != nil {
+							return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+						}
+
+						cache, err := openCache(path)
+						if err != nil {
+							color.Red(err.Error())
+							return nil
+						}
+						defer cache.close()
+
+						removed, err := cache.prune()
+						if err != nil {
+							color.Red(err.Error())
+							return nil
+						}
+
+						color.Green("Success: %d entries removed", removed)
+
+						return nil
+					},
+				},
+			},
+		},
 	}
 
 	err := app.Run(os.Args)
 	if err != nil {
-		log.Fatal(err)
+		color.Red(err.Error())
+		os.Exit(exitUsage)
 	}
 }
 
+//usageError exits with the usage code on unknown or malformed flags
+func usageError(c *cli.Context, err error, isSubcommand bool) error {
+	return cli.NewExitError(color.RedString(err.Error()), exitUsage)
+}
+
 func isAudioFile(extension string) bool {
 	switch extension {
 	case
@@ -207,23 +423,67 @@ func containsString(s []string, e string) bool {
 	return false
 }
 
-func isAlbumDirectory(path string) bool {
-	files, err := ioutil.ReadDir(path)
+var cacheFlag = cli.StringFlag{
+	Name:  "cache",
+	Usage: "Scan cache file.",
+	Value: defaultCachePath(),
+}
+
+var configFlag = cli.StringFlag{
+	Name:  "config",
+	Usage: "Configuration file (default: " + configFileNames[0] + " found from the root upwards).",
+}
+
+//commandCachePath returns the cache file of a command: --cache, then the configuration file cache, then the default
+func commandCachePath(c *cli.Context, cfg *Config) string {
+	if c.IsSet("cache") == false && cfg.Cache != "" {
+		return cfg.Cache
+	}
+
+	return c.String("cache")
+}
+
+//cacheCommandPath returns the cache file of the cache subcommands, looking for the
+//configuration file from the given root or the working directory
+func cacheCommandPath(c *cli.Context) (string, error) {
+	root := c.Args().First()
+	if root == "" {
+		root = "."
+	}
+
+	cfg, err := loadConfig(c.String("config"), root)
 	if err != nil {
-		return false
+		return "", err
 	}
 
-	for _, file := range files {
-		if file.IsDir() == false && isAudioFile(filepath.Ext(file.Name())) == true {
-			return true
-		}
+	return commandCachePath(c, cfg), nil
+}
+
+//openCommandCache opens the scan cache of a command, or returns nil when it is disabled or unavailable
+func openCommandCache(c *cli.Context, cfg *Config) *scanCache {
+	if c.Bool("no-cache") == true {
+		return nil
 	}
 
-	return false
+	path := commandCachePath(c, cfg)
+	if path == "" {
+		return nil
+	}
+
+	cache, err := openCache(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "scan cache disabled: %v\n", err)
+		return nil
+	}
+
+	return cache
 }
 
 func getTrackMetaData(path string) (tag.Metadata, error) {
 	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
 	defer file.Close()
 
 	m, err := tag.ReadFrom(file)
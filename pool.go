@@ -0,0 +1,69 @@
+package main
+
+import (
+	"runtime"
+	"sync"
+)
+
+var defaultJobs = runtime.NumCPU()
+
+//forEachOrdered calls work for every index in [0, count) on at most jobs goroutines
+//and emit in index order on the calling goroutine. Work is only scheduled a few
+//indexes ahead of emit, and stops as soon as emit returns false.
+func forEachOrdered(count int, jobs int, work func(i int), emit func(i int) bool) {
+	if jobs < 1 {
+		jobs = 1
+	}
+
+	type job struct {
+		index int
+		done  chan struct{}
+	}
+
+	pending := make(chan job)
+	order := make(chan job, jobs*4)
+	stop := make(chan struct{})
+
+	var wg sync.WaitGroup
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := range pending {
+				work(j.index)
+				close(j.done)
+			}
+		}()
+	}
+
+	go func() {
+		defer close(order)
+		defer close(pending)
+
+		for i := 0; i < count; i++ {
+			j := job{index: i, done: make(chan struct{})}
+
+			select {
+			case order <- j:
+			case <-stop:
+				return
+			}
+
+			select {
+			case pending <- j:
+			case <-stop:
+				return
+			}
+		}
+	}()
+
+	for j := range order {
+		<-j.done
+		if emit(j.index) == false {
+			break
+		}
+	}
+
+	close(stop)
+	wg.Wait()
+}
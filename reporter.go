@@ -0,0 +1,165 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	color "github.com/fatih/color"
+)
+
+const (
+	sectionAlbums  = "albums"
+	sectionTracks  = "tracks"
+	sectionLibrary = "library"
+)
+
+//Reporter receives check results while the library is checked
+type Reporter interface {
+	StartSection(section string, rules []RuleInfo)
+	Report(section string, path string, findings []Finding)
+	LimitReached(section string)
+	EndSection(section string, summary SectionSummary)
+	Finish(summary Summary) error
+}
+
+var reportFormats = []string{"console", "json", "ndjson", "sarif", "junit"}
+
+func newReporter(format string, w io.Writer, root string) (Reporter, error) {
+	switch format {
+	case "", "console":
+		return &consoleReporter{}, nil
+	case "json":
+		return &jsonReporter{w: w, findings: []Finding{}}, nil
+	case "ndjson":
+		return &ndjsonReporter{enc: json.NewEncoder(w)}, nil
+	case "sarif":
+		return &sarifReporter{w: w, root: root}, nil
+	case "junit":
+		return &junitReporter{w: w, root: root}, nil
+	}
+
+	return nil, fmt.Errorf("unknown format %q", format)
+}
+
+//consoleReporter prints colored findings for humans
+type consoleReporter struct{}
+
+func (r *consoleReporter) StartSection(section string, rules []RuleInfo) {
+	switch section {
+	case sectionAlbums:
+		color.Green("\n// Check Albums //\n")
+	case sectionLibrary:
+		color.Green("\n// Check Library //\n")
+	default:
+		color.Green("\n// Check Tracks //\n")
+	}
+}
+
+func (r *consoleReporter) Report(section string, path string, findings []Finding) {
+	if len(findings) == 0 {
+		return
+	}
+
+	switch section {
+	case sectionAlbums:
+		color.Cyan("Check directory %s", path)
+	case sectionLibrary:
+		color.Cyan("Check %s", path)
+	default:
+		color.Cyan("Check file %s", path)
+	}
+
+	for _, f := range findings {
+		if f.Severity == SeverityError {
+			color.Red(f.Message)
+		}
+	}
+
+	for _, f := range findings {
+		if f.Severity != SeverityError {
+			color.Yellow(f.Message)
+		}
+	}
+}
+
+func (r *consoleReporter) LimitReached(section string) {
+	red := color.New(color.FgRed)
+	whiteBackground := red.Add(color.BgWhite)
+	whiteBackground.Println("Error count limit reached")
+}
+
+func (r *consoleReporter) EndSection(section string, s SectionSummary) {
+	name := "tracks"
+	if section == sectionAlbums {
+		name = "album"
+	}
+	if section == sectionLibrary {
+		name = "library"
+	}
+
+	color.Green("\nTotal checked %s: %d\n", name, s.Checked)
+	color.Red("Total errored %s: %d\n", name, s.Errors)
+	color.Yellow("Total warning %s: %d\n", name, s.Warnings)
+	if s.Suppressed > 0 {
+		color.Cyan("Total suppressed %s: %d\n", name, s.Suppressed)
+	}
+}
+
+func (r *consoleReporter) Finish(summary Summary) error {
+	return nil
+}
+
+//jsonReporter writes one document holding all findings and the summary
+type jsonReporter struct {
+	w            io.Writer
+	findings     []Finding
+	limitReached bool
+}
+
+func (r *jsonReporter) StartSection(section string, rules []RuleInfo) {}
+
+func (r *jsonReporter) Report(section string, path string, findings []Finding) {
+	r.findings = append(r.findings, findings...)
+}
+
+func (r *jsonReporter) LimitReached(section string) {
+	r.limitReached = true
+}
+
+func (r *jsonReporter) EndSection(section string, summary SectionSummary) {}
+
+func (r *jsonReporter) Finish(summary Summary) error {
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(struct {
+		Findings     []Finding `json:"findings"`
+		Summary      Summary   `json:"summary"`
+		LimitReached bool      `json:"limit_reached"`
+	}{r.findings, summary, r.limitReached})
+}
+
+//ndjsonReporter streams one finding per line
+type ndjsonReporter struct {
+	enc *json.Encoder
+	err error
+}
+
+func (r *ndjsonReporter) StartSection(section string, rules []RuleInfo) {}
+
+func (r *ndjsonReporter) Report(section string, path string, findings []Finding) {
+	for _, f := range findings {
+		if r.err == nil {
+			r.err = r.enc.Encode(f)
+		}
+	}
+}
+
+func (r *ndjsonReporter) LimitReached(section string) {}
+
+func (r *ndjsonReporter) EndSection(section string, summary SectionSummary) {}
+
+func (r *ndjsonReporter) Finish(summary Summary) error {
+	return r.err
+}
@@ -0,0 +1,266 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+)
+
+//Capitalization styles
+const (
+	styleTitle    = "title"
+	styleSentence = "sentence"
+	styleAsIs     = "as-is"
+)
+
+//defaultSmallWords stay lower case inside English titles
+var defaultSmallWords = []string{
+	"a", "an", "and", "as", "at", "but", "by", "en", "for", "if", "in", "nor",
+	"of", "on", "or", "per", "the", "to", "v", "vs", "via",
+}
+
+//defaultCaseExceptions keep their spelling in title and sentence styles
+var defaultCaseExceptions = []string{
+	"I", "II", "III", "IV", "VI", "VII", "VIII", "IX", "XI", "XII",
+	"DJ", "MC", "OK", "TV", "UK", "USA",
+}
+
+func init() {
+	RegisterTrackRule(newCaseRule("title-case", "Title does not follow the capitalization style.", "title"))
+	RegisterTrackRule(newCaseRule("album-case", "Album does not follow the capitalization style.", "album"))
+	RegisterTrackRule(newCaseRule("artist-case", "Artist or album artist does not follow the capitalization style.", "artist", "album_artist"))
+}
+
+//caseRule checks tag fields against a capitalization style. The default as-is style
+//only enforces the exceptions spelling.
+type caseRule struct {
+	id          string
+	description string
+	fields      []string
+	style       string
+	smallWords  []string
+	exceptions  map[string]string
+}
+
+func newCaseRule(id string, description string, fields ...string) *caseRule {
+	r := &caseRule{id: id, description: description, fields: fields}
+	r.configure(RuleOptions{})
+
+	return r
+}
+
+func (r *caseRule) ID() string { return r.id }
+
+func (r *caseRule) Description() string { return r.description }
+
+func (r *caseRule) Severity() Severity { return SeverityWarning }
+
+func (r *caseRule) Configure(options RuleOptions) (Configurable, error) {
+	configured := *r
+
+	return &configured, configured.configure(options)
+}
+
+func (r *caseRule) configure(options RuleOptions) error {
+	if err := options.checkKeys("style", "small_words", "exceptions"); err != nil {
+		return err
+	}
+
+	style, err := options.String("style", styleAsIs)
+	if err != nil {
+		return err
+	}
+	if style != styleTitle && style != styleSentence && style != styleAsIs {
+		return fmt.Errorf("unknown style %q, expected %s, %s or %s", style, styleTitle, styleSentence, styleAsIs)
+	}
+
+	smallWords, err := options.Strings("small_words", defaultSmallWords)
+	if err != nil {
+		return err
+	}
+
+	//as-is only enforces configured exceptions: "vi" or "ok" are words in other languages
+	exceptions := defaultCaseExceptions
+	if style == styleAsIs {
+		exceptions = nil
+	}
+	exceptions, err = options.Strings("exceptions", exceptions)
+	if err != nil {
+		return err
+	}
+
+	r.style = style
+	r.smallWords = smallWords
+	r.exceptions = map[string]string{}
+	for _, word := range exceptions {
+		r.exceptions[strings.ToLower(word)] = word
+	}
+
+	return nil
+}
+
+func (r *caseRule) Check(track *TrackFile) []Finding {
+	var findings []Finding
+	for _, field := range r.fields {
+		value := tagField(track, field)
+		if value == "" {
+			continue
+		}
+
+		expected := r.recase(value)
+		if expected == value {
+			continue
+		}
+
+		message := fmt.Sprintf("%s does not follow %s case (%s).", fieldLabel(field), r.style, value)
+		if r.style == styleAsIs {
+			message = fmt.Sprintf("%s does not follow the exceptions spelling (%s).", fieldLabel(field), value)
+		}
+
+		findings = append(findings, Finding{
+			Field:    field,
+			Value:    value,
+			Expected: expected,
+			Message:  message,
+		})
+	}
+
+	return findings
+}
+
+//recase returns s in the rule style
+func (r *caseRule) recase(s string) string {
+	words := strings.Split(s, " ")
+
+	first, last := -1, -1
+	for i, word := range words {
+		if word != "" {
+			if first < 0 {
+				first = i
+			}
+			last = i
+		}
+	}
+
+	//words of a text written in capitals are not acronyms
+	var shouting = strings.ToUpper(s) == s && strings.ToLower(s) != s
+
+	var phraseStart = true
+	for i, word := range words {
+		if word == "" {
+			continue
+		}
+
+		lead, core, trail := splitWordPunctuation(word)
+		if lead != "" && strings.ContainsAny(lead, "([\"") {
+			phraseStart = true
+		}
+
+		if core != "" {
+			core = r.recaseWord(core, phraseStart || i == first, i == last, shouting)
+			words[i] = lead + core + trail
+			phraseStart = false
+		}
+
+		if strings.ContainsAny(trail, ":.!?") || word == "-" {
+			phraseStart = true
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func (r *caseRule) recaseWord(word string, start bool, last bool, shouting bool) string {
+	if exception, exists := r.exceptions[strings.ToLower(word)]; exists == true {
+		return exception
+	}
+
+	//stylized words as "McCartney", "deadmau5" or acronyms are left alone
+	if strings.IndexFunc(word, unicode.IsDigit) >= 0 || mixedCase(word) == true {
+		return word
+	}
+	if shouting == false && len([]rune(word)) > 1 && strings.ToUpper(word) == word {
+		return word
+	}
+
+	switch r.style {
+	case styleTitle:
+		parts := strings.Split(word, "-")
+		for i, part := range parts {
+			lower := strings.ToLower(part)
+			if containsString(r.smallWords, lower) == true && (i > 0 || (start == false && last == false)) {
+				parts[i] = lower
+			} else {
+				parts[i] = capitalize(lower)
+			}
+		}
+		return strings.Join(parts, "-")
+	case styleSentence:
+		if start == true {
+			return capitalize(strings.ToLower(word))
+		}
+		return strings.ToLower(word)
+	}
+
+	return word
+}
+
+//splitWordPunctuation splits a word into leading punctuation, core and trailing punctuation
+func splitWordPunctuation(word string) (string, string, string) {
+	isWordRune := func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }
+
+	start := strings.IndexFunc(word, isWordRune)
+	if start < 0 {
+		return word, "", ""
+	}
+	end := strings.LastIndexFunc(word, isWordRune)
+	end += len(string([]rune(word[end:])[0]))
+
+	return word[:start], word[start:end], word[end:]
+}
+
+//mixedCase tells if a word has an upper case letter after a lower case one, as "McCartney" or "iPhone"
+func mixedCase(word string) bool {
+	var seenLower = false
+	for _, r := range word {
+		if unicode.IsLower(r) {
+			seenLower = true
+		} else if unicode.IsUpper(r) && seenLower == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+func capitalize(word string) string {
+	for i, r := range word {
+		return word[:i] + string(unicode.ToUpper(r)) + word[i+len(string(r)):]
+	}
+
+	return word
+}
+
+//tagField returns a text tag by its export name
+func tagField(track *TrackFile, field string) string {
+	switch field {
+	case "title":
+		return track.Title()
+	case "album":
+		return track.Album()
+	case "artist":
+		return track.Artist()
+	case "album_artist":
+		return track.AlbumArtist()
+	case "composer":
+		return track.Composer()
+	case "genre":
+		return track.Genre()
+	}
+
+	return ""
+}
+
+func fieldLabel(field string) string {
+	return capitalize(strings.Replace(field, "_", " ", -1))
+}
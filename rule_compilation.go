@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+//defaultMinCompilationArtists is the number of artists making a directory without album artist a compilation
+const defaultMinCompilationArtists = 3
+
+//compilationTags are the raw tags holding the compilation flag: ID3v2.3/2.4, ID3v2.2, MP4 and Vorbis comments
+var compilationTags = []string{"TCMP", "TCP", "cpil", "COMPILATION"}
+
+func init() {
+	RegisterAlbumRule(NewAlbumRule("various-artists-not-compilation", "Album artist is Various Artists but the compilation flag is not set.", SeverityWarning, variousArtistsNotCompilationRule))
+	RegisterAlbumRule(NewAlbumRule("single-artist-compilation", "Compilation flag is set on an album with a single artist.", SeverityWarning, singleArtistCompilationRule))
+	RegisterAlbumRule(NewAlbumRuleWithOptions("missing-compilation-album-artist", "Directory tracks have many different artists but no album artist.", SeverityWarning, RuleOptions{"min_artists": defaultMinCompilationArtists}, missingCompilationAlbumArtistRule))
+}
+
+//isCompilation tells if the compilation flag of a track is set
+func isCompilation(track *TrackFile) bool {
+	for key, value := range track.Raw() {
+		if containsFold(compilationTags, key) == false {
+			continue
+		}
+
+		if compilationFlag(value) == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+//compilationFlag tells if a raw compilation tag value is set. ID3 frames and Vorbis comments
+//hold a string, MP4 "cpil" atoms an integer, a boolean or bytes depending on how they were read.
+func compilationFlag(value interface{}) bool {
+	switch v := value.(type) {
+	case string:
+		switch strings.ToLower(strings.TrimSpace(v)) {
+		case "1", "true", "yes":
+			return true
+		}
+	case bool:
+		return v
+	case int:
+		return v != 0
+	case uint8:
+		return v != 0
+	case []byte:
+		for _, b := range v {
+			if b != 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func containsFold(values []string, s string) bool {
+	for _, value := range values {
+		if strings.EqualFold(value, s) == true {
+			return true
+		}
+	}
+
+	return false
+}
+
+//artistCounts counts the tracks of every artist of a directory, empty artists excluded
+func artistCounts(album *AlbumDir) valueCounts {
+	counts := valueCounts{}
+	for _, track := range album.Tracks {
+		if artist := strings.TrimSpace(track.Artist()); artist != "" {
+			counts[artist]++
+		}
+	}
+
+	return counts
+}
+
+func variousArtistsNotCompilationRule(album *AlbumDir, options RuleOptions) []Finding {
+	var findings []Finding
+	for _, track := range album.Tracks {
+		if album.library.isVariousArtists(track.AlbumArtist()) == false || isCompilation(track) == true {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Path:     track.Path,
+			Field:    "compilation",
+			Expected: "1",
+			Message:  fmt.Sprintf("Album artist is %s but the compilation flag is not set.", track.AlbumArtist()),
+		})
+	}
+
+	return findings
+}
+
+func singleArtistCompilationRule(album *AlbumDir, options RuleOptions) []Finding {
+	var flagged int
+	for _, track := range album.Tracks {
+		if isCompilation(track) == true {
+			flagged++
+		}
+	}
+
+	artists := artistCounts(album)
+	//a single track tells nothing about the album
+	if flagged == 0 || len(artists) != 1 || len(album.Tracks) < 2 {
+		return nil
+	}
+
+	return []Finding{{
+		Field:    "compilation",
+		Value:    "1",
+		Expected: "0",
+		Message:  fmt.Sprintf("Compilation flag is set on %d tracks but all tracks are by %s.", flagged, artists.sorted()[0]),
+	}}
+}
+
+func missingCompilationAlbumArtistRule(album *AlbumDir, options RuleOptions) []Finding {
+	for _, track := range album.Tracks {
+		if sanitizeString(track.AlbumArtist()) != "" {
+			return nil
+		}
+	}
+
+	minArtists, _ := options.Int("min_artists", defaultMinCompilationArtists)
+	artists := artistCounts(album)
+	if len(artists) < minArtists {
+		return nil
+	}
+
+	return []Finding{{
+		Field:    "album_artist",
+		Expected: "Various Artists",
+		Message:  fmt.Sprintf("Directory tracks have %d different artists but no album artist.", len(artists)),
+	}}
+}
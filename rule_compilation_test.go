@@ -0,0 +1,58 @@
+package main
+
+import (
+	"testing"
+
+	tag "github.com/dhowden/tag"
+)
+
+//rawMetadata is a tag.Metadata holding raw tags only
+type rawMetadata map[string]interface{}
+
+func (m rawMetadata) Format() tag.Format          { return tag.UnknownFormat }
+func (m rawMetadata) FileType() tag.FileType      { return tag.UnknownFileType }
+func (m rawMetadata) Title() string               { return "" }
+func (m rawMetadata) Album() string               { return "" }
+func (m rawMetadata) Artist() string              { return "" }
+func (m rawMetadata) AlbumArtist() string         { return "" }
+func (m rawMetadata) Composer() string            { return "" }
+func (m rawMetadata) Year() int                   { return 0 }
+func (m rawMetadata) Genre() string               { return "" }
+func (m rawMetadata) Track() (int, int)           { return 0, 0 }
+func (m rawMetadata) Disc() (int, int)            { return 0, 0 }
+func (m rawMetadata) Picture() *tag.Picture       { return nil }
+func (m rawMetadata) Lyrics() string              { return "" }
+func (m rawMetadata) Comment() string             { return "" }
+func (m rawMetadata) Raw() map[string]interface{} { return m }
+
+func TestIsCompilation(t *testing.T) {
+	tests := []struct {
+		name string
+		raw  rawMetadata
+		want bool
+	}{
+		{"no flag", rawMetadata{}, false},
+		{"id3v2.4 string", rawMetadata{"TCMP": "1"}, true},
+		{"id3v2.4 string unset", rawMetadata{"TCMP": "0"}, false},
+		{"id3v2.2 string", rawMetadata{"TCP": "1"}, true},
+		{"vorbis string", rawMetadata{"compilation": " Yes "}, true},
+		{"vorbis string unset", rawMetadata{"COMPILATION": "no"}, false},
+		{"mp4 int", rawMetadata{"cpil": 1}, true},
+		{"mp4 int unset", rawMetadata{"cpil": 0}, false},
+		{"mp4 bool", rawMetadata{"cpil": true}, true},
+		{"mp4 bool unset", rawMetadata{"cpil": false}, false},
+		{"mp4 byte", rawMetadata{"cpil": byte(1)}, true},
+		{"mp4 byte unset", rawMetadata{"cpil": byte(0)}, false},
+		{"mp4 bytes", rawMetadata{"cpil": []byte{1}}, true},
+		{"mp4 bytes unset", rawMetadata{"cpil": []byte{0}}, false},
+		{"other tag", rawMetadata{"TXXX": "1"}, false},
+	}
+
+	for _, test := range tests {
+		//tags are read through a trackTags copy, as the checker does
+		track := &TrackFile{Path: test.name, trackTags: newTrackTags(test.raw)}
+		if got := isCompilation(track); got != test.want {
+			t.Errorf("%s: isCompilation = %v, want %v", test.name, got, test.want)
+		}
+	}
+}
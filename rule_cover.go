@@ -0,0 +1,147 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+//default minimum cover width and height, in pixels
+const defaultMinCoverSize = 500
+
+//default accepted difference between cover sides, in percent
+const defaultCoverTolerance = 1
+
+func init() {
+	RegisterAlbumRule(NewAlbumRule("missing-cover", "No track of the directory embeds a cover.", SeverityWarning, missingCoverRule))
+	RegisterAlbumRule(NewAlbumRule("partial-cover", "Only some tracks of the directory embed a cover.", SeverityWarning, partialCoverRule))
+
+	RegisterTrackRule(NewTrackRuleWithOptions("small-cover", "Embedded cover is smaller than the minimum size.", SeverityWarning, RuleOptions{"min_size": defaultMinCoverSize}, smallCoverRule))
+	RegisterTrackRule(NewTrackRuleWithOptions("non-square-cover", "Embedded cover is not square.", SeverityWarning, RuleOptions{"tolerance": defaultCoverTolerance}, nonSquareCoverRule))
+	RegisterTrackRule(NewTrackRule("cover-type-mismatch", "Embedded cover MIME type or extension does not match the image data.", SeverityWarning, coverTypeMismatchRule))
+	RegisterTrackRule(NewTrackRule("undecodable-cover", "Embedded cover cannot be decoded.", SeverityError, undecodableCoverRule))
+}
+
+func countCovers(album *AlbumDir) int {
+	var count = 0
+	for _, track := range album.Tracks {
+		if track.Picture() != nil {
+			count++
+		}
+	}
+
+	return count
+}
+
+func missingCoverRule(album *AlbumDir, options RuleOptions) []Finding {
+	if len(album.Tracks) == 0 || countCovers(album) > 0 {
+		return nil
+	}
+
+	return []Finding{{Field: "picture", Message: "Directory tracks have no embedded cover."}}
+}
+
+func partialCoverRule(album *AlbumDir, options RuleOptions) []Finding {
+	count := countCovers(album)
+	if count == 0 || count == len(album.Tracks) {
+		return nil
+	}
+
+	return []Finding{{
+		Field:    "picture",
+		Value:    fmt.Sprintf("%d/%d", count, len(album.Tracks)),
+		Expected: fmt.Sprintf("%d/%d", len(album.Tracks), len(album.Tracks)),
+		Message:  fmt.Sprintf("Only %d of %d directory tracks embed a cover.", count, len(album.Tracks)),
+	}}
+}
+
+//decodedCover returns the cover of a track when it could be decoded
+func decodedCover(track *TrackFile) *coverInfo {
+	cover := track.Cover()
+	if cover == nil || cover.DecodeError != "" {
+		return nil
+	}
+
+	return cover
+}
+
+func smallCoverRule(track *TrackFile, options RuleOptions) []Finding {
+	cover := decodedCover(track)
+	if cover == nil {
+		return nil
+	}
+
+	minSize, _ := options.Int("min_size", defaultMinCoverSize)
+	if cover.Width >= minSize && cover.Height >= minSize {
+		return nil
+	}
+
+	return []Finding{{
+		Field:    "picture",
+		Value:    fmt.Sprintf("%dx%d", cover.Width, cover.Height),
+		Expected: fmt.Sprintf("%dx%d", minSize, minSize),
+		Message:  fmt.Sprintf("Cover is smaller than %dx%d (%dx%d).", minSize, minSize, cover.Width, cover.Height),
+	}}
+}
+
+func nonSquareCoverRule(track *TrackFile, options RuleOptions) []Finding {
+	cover := decodedCover(track)
+	if cover == nil || cover.Width == 0 || cover.Height == 0 {
+		return nil
+	}
+
+	//tolerance is the accepted difference between sides, in percent of the longest one
+	tolerance, _ := options.Int("tolerance", defaultCoverTolerance)
+	longest := math.Max(float64(cover.Width), float64(cover.Height))
+	if math.Abs(float64(cover.Width-cover.Height))*100 <= float64(tolerance)*longest {
+		return nil
+	}
+
+	return []Finding{{
+		Field:   "picture",
+		Value:   fmt.Sprintf("%dx%d", cover.Width, cover.Height),
+		Message: fmt.Sprintf("Cover is not square (%dx%d).", cover.Width, cover.Height),
+	}}
+}
+
+func coverTypeMismatchRule(track *TrackFile, options RuleOptions) []Finding {
+	picture, cover := track.Picture(), track.Cover()
+	if picture == nil || cover == nil || cover.DetectedType == "" {
+		return nil
+	}
+
+	var findings []Finding
+	if picture.MIMEType != "" && sameImageType(picture.MIMEType, cover.DetectedType) == false {
+		findings = append(findings, Finding{
+			Field:    "picture_mime_type",
+			Value:    picture.MIMEType,
+			Expected: cover.DetectedType,
+			Message:  fmt.Sprintf("Cover MIME type %s does not match the image data (%s).", picture.MIMEType, cover.DetectedType),
+		})
+	}
+
+	if picture.Ext != "" && sameImageType(picture.Ext, cover.DetectedType) == false {
+		findings = append(findings, Finding{
+			Field:    "picture_ext",
+			Value:    picture.Ext,
+			Expected: strings.TrimPrefix(cover.DetectedType, "image/"),
+			Message:  fmt.Sprintf("Cover extension %s does not match the image data (%s).", picture.Ext, cover.DetectedType),
+		})
+	}
+
+	return findings
+}
+
+func undecodableCoverRule(track *TrackFile, options RuleOptions) []Finding {
+	cover := track.Cover()
+	if cover == nil || cover.DecodeError == "" {
+		return nil
+	}
+
+	return []Finding{{
+		Field:   "picture",
+		Value:   strconv.Itoa(cover.Size) + " bytes",
+		Message: fmt.Sprintf("Cover cannot be decoded (%s).", cover.DecodeError),
+	}}
+}
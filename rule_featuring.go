@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//Featured artist conventions
+const (
+	featuringInTitle  = "title"
+	featuringInArtist = "artist"
+)
+
+//defaultFeaturingMarkers introduce featured artists; "&" may be added, at the cost of duos
+var defaultFeaturingMarkers = []string{"feat.", "feat", "ft.", "ft", "featuring", "with", "x"}
+
+//ambiguousFeaturingMarkers are common words, only markers inside parentheses,
+//as in "Stay With Me" or "Bob Seger with The Silver Bullet Band"
+var ambiguousFeaturingMarkers = []string{"with", "x", "&", "and", "+"}
+
+func init() {
+	r := &featuringRule{}
+	r.configure(RuleOptions{})
+
+	RegisterTrackRule(r)
+}
+
+//featuringRule checks that featured artists are credited the same way everywhere:
+//"Song (feat. X)" with the main artist alone, or "Artist feat. X" with a bare title
+type featuringRule struct {
+	convention string
+	marker     string
+	//title matches "(feat. X)" groups anywhere and a trailing "feat. X" without parentheses
+	title *regexp.Regexp
+	//artist matches "Artist feat. X" and "Artist (feat. X)", ambiguous markers only inside parentheses
+	artist *regexp.Regexp
+}
+
+func (r *featuringRule) ID() string { return "featured-artist" }
+
+func (r *featuringRule) Description() string {
+	return "Featured artists do not follow the featuring convention."
+}
+
+func (r *featuringRule) Severity() Severity { return SeverityWarning }
+
+func (r *featuringRule) Configure(options RuleOptions) (Configurable, error) {
+	configured := *r
+
+	return &configured, configured.configure(options)
+}
+
+func (r *featuringRule) configure(options RuleOptions) error {
+	if err := options.checkKeys("convention", "marker", "markers"); err != nil {
+		return err
+	}
+
+	convention, err := options.String("convention", featuringInTitle)
+	if err != nil {
+		return err
+	}
+	if convention != featuringInTitle && convention != featuringInArtist {
+		return fmt.Errorf("unknown convention %q, expected %s or %s", convention, featuringInTitle, featuringInArtist)
+	}
+
+	marker, err := options.String("marker", "feat.")
+	if err != nil {
+		return err
+	}
+
+	markers, err := options.Strings("markers", defaultFeaturingMarkers)
+	if err != nil {
+		return err
+	}
+	if len(markers) == 0 {
+		return fmt.Errorf("markers cannot be empty")
+	}
+
+	var bareMarkers []string
+	for _, marker := range markers {
+		if containsString(ambiguousFeaturingMarkers, strings.ToLower(marker)) == false {
+			bareMarkers = append(bareMarkers, marker)
+		}
+	}
+
+	r.convention = convention
+	r.marker = marker
+	r.title = regexp.MustCompile(`(?i)\s*[(\[]` + markersRegexp(markers) + `\s+([^)\]]+)[)\]]`)
+	if len(bareMarkers) > 0 {
+		r.title = regexp.MustCompile(r.title.String() + `|\s+` + markersRegexp(bareMarkers) + `\s+(.+)$`)
+	}
+	artist := `[(\[]` + markersRegexp(markers) + `\s+([^)\]]+)[)\]]`
+	if len(bareMarkers) > 0 {
+		artist += `|` + markersRegexp(bareMarkers) + `\s+(.+)`
+	}
+	r.artist = regexp.MustCompile(`(?i)^(.+?)\s+(?:` + artist + `)$`)
+
+	return nil
+}
+
+func (r *featuringRule) Check(track *TrackFile) []Finding {
+	title, artist := track.Title(), track.Artist()
+
+	var featured []string
+	addFeatured := func(names string) {
+		names = strings.TrimSpace(names)
+		for _, name := range featured {
+			if strings.EqualFold(name, names) == true {
+				return
+			}
+		}
+		featured = append(featured, names)
+	}
+
+	for _, groups := range r.title.FindAllStringSubmatch(title, -1) {
+		addFeatured(strings.Join(groups[1:], ""))
+	}
+	bareTitle := strings.TrimSpace(r.title.ReplaceAllString(title, ""))
+
+	mainArtist := artist
+	if groups := r.artist.FindStringSubmatch(artist); groups != nil {
+		mainArtist = strings.TrimSpace(groups[1])
+		addFeatured(strings.Join(groups[2:], ""))
+	}
+
+	if len(featured) == 0 {
+		return nil
+	}
+
+	credit := r.marker + " " + strings.Join(featured, " & ")
+	expectedTitle, expectedArtist := bareTitle+" ("+credit+")", mainArtist
+	if r.convention == featuringInArtist {
+		expectedTitle, expectedArtist = bareTitle, mainArtist+" "+credit
+	}
+
+	if expectedTitle == title && expectedArtist == artist {
+		return nil
+	}
+
+	field, value, expected := "title", title, expectedTitle
+	if expectedTitle == title {
+		field, value, expected = "artist", artist, expectedArtist
+	}
+
+	return []Finding{{
+		Field:     field,
+		Value:     value,
+		Expected:  expected,
+		Suggested: map[string]string{"title": expectedTitle, "artist": expectedArtist},
+		Message:   fmt.Sprintf("Featured artists should be credited in the %s (%s / %s).", r.convention, expectedTitle, expectedArtist),
+	}}
+}
+
+//markersRegexp matches any of markers, longest first so "feat." wins over "feat"
+func markersRegexp(markers []string) string {
+	sorted := append([]string{}, markers...)
+	sort.Slice(sorted, func(i, j int) bool { return len(sorted[i]) > len(sorted[j]) })
+
+	var quoted []string
+	for _, marker := range sorted {
+		//single letters stay case sensitive: "x" is a marker, "Malcolm X" is not
+		if len([]rune(marker)) == 1 {
+			quoted = append(quoted, "(?-i:"+regexp.QuoteMeta(marker)+")")
+			continue
+		}
+		quoted = append(quoted, regexp.QuoteMeta(marker))
+	}
+
+	return "(?:" + strings.Join(quoted, "|") + ")"
+}
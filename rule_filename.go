@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterTrackRule(&fileNameRule{ignoreCase: true})
+}
+
+//fileNameRule compares file names with tags through a naming template.
+//It stays silent until a template is configured.
+type fileNameRule struct {
+	template   *namingTemplate
+	ignoreCase bool
+}
+
+func (r *fileNameRule) ID() string { return "filename-template" }
+
+func (r *fileNameRule) Description() string {
+	return "File name does not match the tags through the naming template."
+}
+
+func (r *fileNameRule) Severity() Severity { return SeverityWarning }
+
+func (r *fileNameRule) Configure(options RuleOptions) (Configurable, error) {
+	configured := *r
+
+	return &configured, configured.configure(options)
+}
+
+func (r *fileNameRule) configure(options RuleOptions) error {
+	if err := options.checkKeys("template", "ignore_case"); err != nil {
+		return err
+	}
+
+	ignoreCase, err := options.Bool("ignore_case", true)
+	if err != nil {
+		return err
+	}
+	r.ignoreCase = ignoreCase
+
+	pattern, err := options.String("template", "")
+	if err != nil {
+		return err
+	}
+	if pattern == "" {
+		r.template = nil
+		return nil
+	}
+
+	t, err := parseNamingTemplate(pattern)
+	if err != nil {
+		return err
+	}
+	r.template = t
+
+	return nil
+}
+
+func (r *fileNameRule) Check(track *TrackFile) []Finding {
+	if r.template == nil {
+		return nil
+	}
+
+	name := filepath.Base(track.Path)
+	values := trackTemplateValues(track)
+
+	matched, mismatches := r.template.compare(name, values, r.ignoreCase)
+	if matched == false {
+		return []Finding{{
+			Field:    "filename",
+			Value:    name,
+			Expected: r.template.render(values),
+			Suspect:  SuspectFileName,
+			Message:  fmt.Sprintf("File name does not follow the template %s (%s).", r.template.pattern, name),
+		}}
+	}
+
+	var findings []Finding
+	for _, m := range mismatches {
+		if tagLooksWrong(track.library, m) == true {
+			findings = append(findings, Finding{
+				Field:    m.field,
+				Value:    m.tagValue,
+				Expected: m.nameValue,
+				Suspect:  SuspectTag,
+				Message:  fmt.Sprintf("Tag %s (%s) does not match the file name (%s).", m.field, m.tagValue, m.nameValue),
+			})
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Field:    "filename",
+			Value:    name,
+			Expected: r.template.render(values),
+			Suspect:  SuspectFileName,
+			Message:  fmt.Sprintf("File name %s (%s) does not match the tag (%s).", m.field, m.nameValue, m.tagValue),
+		})
+	}
+
+	return findings
+}
+
+//tagLooksWrong tells if the tag, rather than the file name, is the likely culprit of a mismatch:
+//an empty tag, or a placeholder tag such as "Track 01" next to a real name
+func tagLooksWrong(lib *Library, m templateMismatch) bool {
+	tagValue := strings.TrimSpace(m.tagValue)
+	if tagValue == "" || tagValue == "0" {
+		return true
+	}
+
+	matcher := lib.suspiciousMatcher(m.field)
+
+	return matcher.match(tagValue) != "" && matcher.match(m.nameValue) == ""
+}
@@ -0,0 +1,130 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var audioProperties = []string{"container", "codec", "sample_rate", "bit_depth", "bitrate"}
+
+//defaultBitrateTolerance is the accepted difference between constant bitrates, in percent
+const defaultBitrateTolerance = 10
+
+func init() {
+	RegisterAlbumRule(NewAlbumRuleWithOptions("mixed-format", "Directory tracks mix containers, codecs, sample rates, bit depths or bitrates.", SeverityWarning, RuleOptions{"properties": audioProperties, "bitrate_tolerance": defaultBitrateTolerance}, mixedFormatRule))
+}
+
+//valueCounts counts the tracks of every value of a stream property
+type valueCounts map[string]int
+
+//sorted returns the values from the most to the least common
+func (c valueCounts) sorted() []string {
+	var values []string
+	for value := range c {
+		values = append(values, value)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if c[values[i]] != c[values[j]] {
+			return c[values[i]] > c[values[j]]
+		}
+		return values[i] < values[j]
+	})
+
+	return values
+}
+
+func (c valueCounts) String() string {
+	var s []string
+	for _, value := range c.sorted() {
+		s = append(s, fmt.Sprintf("%s (%d)", value, c[value]))
+	}
+
+	return strings.Join(s, ", ")
+}
+
+func mixedFormatRule(album *AlbumDir, options RuleOptions) []Finding {
+	properties, _ := options.Strings("properties", audioProperties)
+	//bitrates of constant bitrate lossy tracks may differ by tolerance percent
+	tolerance, _ := options.Int("bitrate_tolerance", defaultBitrateTolerance)
+
+	counts := map[string]valueCounts{}
+	for _, property := range audioProperties {
+		counts[property] = valueCounts{}
+	}
+
+	var minBitrate, maxBitrate = 0, 0
+	for _, track := range album.Tracks {
+		audio := track.Audio()
+		if audio == nil {
+			continue
+		}
+
+		counts["container"][audio.Container]++
+		counts["codec"][audio.Codec]++
+		counts["sample_rate"][strconv.Itoa(audio.SampleRate)+" Hz"]++
+
+		if audio.Lossless == true {
+			counts["bit_depth"][strconv.Itoa(audio.BitDepth)+" bits"]++
+			continue
+		}
+
+		switch {
+		case audio.VBR == true:
+			counts["bitrate"]["VBR"]++
+		case audio.Bitrate > 0:
+			counts["bitrate"][strconv.Itoa(audio.Bitrate)+" kbps"]++
+			if minBitrate == 0 || audio.Bitrate < minBitrate {
+				minBitrate = audio.Bitrate
+			}
+			if audio.Bitrate > maxBitrate {
+				maxBitrate = audio.Bitrate
+			}
+		}
+	}
+
+	//a mix of containers is a mix of codecs too, unless one container holds several codecs
+	mixedContainers := len(counts["container"]) > 1
+	mixedCodecs := len(counts["codec"]) > 1 && (mixedContainers == false || len(counts["codec"]) > len(counts["container"]))
+	_, vbr := counts["bitrate"]["VBR"]
+	mixedBitrates := (vbr == true && len(counts["bitrate"]) > 1) || maxBitrate*100 > minBitrate*(100+tolerance)
+
+	mixed := map[string]bool{
+		"container":   mixedContainers,
+		"codec":       mixedCodecs,
+		"sample_rate": len(counts["sample_rate"]) > 1,
+		"bit_depth":   len(counts["bit_depth"]) > 1,
+		"bitrate":     mixedBitrates,
+	}
+
+	var findings []Finding
+	for _, property := range properties {
+		if mixed[property] == false {
+			continue
+		}
+
+		c := counts[property]
+		findings = append(findings, Finding{
+			Field:    property,
+			Value:    c.String(),
+			Expected: c.sorted()[0],
+			Message:  fmt.Sprintf("Directory mixes %s (%s).", propertyLabel(property), c.String()),
+		})
+	}
+
+	return findings
+}
+
+func propertyLabel(property string) string {
+	switch property {
+	case "sample_rate":
+		return "sample rates"
+	case "bit_depth":
+		return "bit depths"
+	case "bitrate":
+		return "bitrates"
+	}
+
+	return property + "s"
+}
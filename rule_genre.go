@@ -0,0 +1,227 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//id3v1Genres are the ID3v1 genre names, Winamp extensions included, by code
+var id3v1Genres = []string{
+	"Blues", "Classic Rock", "Country", "Dance", "Disco", "Funk", "Grunge", "Hip-Hop",
+	"Jazz", "Metal", "New Age", "Oldies", "Other", "Pop", "R&B", "Rap",
+	"Reggae", "Rock", "Techno", "Industrial", "Alternative", "Ska", "Death Metal", "Pranks",
+	"Soundtrack", "Euro-Techno", "Ambient", "Trip-Hop", "Vocal", "Jazz+Funk", "Fusion", "Trance",
+	"Classical", "Instrumental", "Acid", "House", "Game", "Sound Clip", "Gospel", "Noise",
+	"AlternRock", "Bass", "Soul", "Punk", "Space", "Meditative", "Instrumental Pop", "Instrumental Rock",
+	"Ethnic", "Gothic", "Darkwave", "Techno-Industrial", "Electronic", "Pop-Folk", "Eurodance", "Dream",
+	"Southern Rock", "Comedy", "Cult", "Gangsta", "Top 40", "Christian Rap", "Pop/Funk", "Jungle",
+	"Native American", "Cabaret", "New Wave", "Psychadelic", "Rave", "Showtunes", "Trailer", "Lo-Fi",
+	"Tribal", "Acid Punk", "Acid Jazz", "Polka", "Retro", "Musical", "Rock & Roll", "Hard Rock",
+	"Folk", "Folk-Rock", "National Folk", "Swing", "Fast Fusion", "Bebob", "Latin", "Revival",
+	"Celtic", "Bluegrass", "Avantgarde", "Gothic Rock", "Progressive Rock", "Psychedelic Rock", "Symphonic Rock", "Slow Rock",
+	"Big Band", "Chorus", "Easy Listening", "Acoustic", "Humour", "Speech", "Chanson", "Opera",
+	"Chamber Music", "Sonata", "Symphony", "Booty Bass", "Primus", "Porn Groove", "Satire", "Slow Jam",
+	"Club", "Tango", "Samba", "Folklore", "Ballad", "Power Ballad", "Rhythmic Soul", "Freestyle",
+	"Duet", "Punk Rock", "Drum Solo", "A capella", "Euro-House", "Dance Hall", "Goa", "Drum & Bass",
+	"Club-House", "Hardcore", "Terror", "Indie", "BritPop", "Negerpunk", "Polsk Punk", "Beat",
+	"Christian Gangsta Rap", "Heavy Metal", "Black Metal", "Crossover", "Contemporary Christian", "Christian Rock", "Merengue", "Salsa",
+	"Thrash Metal", "Anime", "JPop", "Synthpop",
+}
+
+//numericGenreRegexp matches ID3v1 codes left in genre tags: "17", "(17)" or "(17)Rock"
+var numericGenreRegexp = regexp.MustCompile(`^(?:\((\d+)\)(.*)|(\d+))$`)
+
+//defaultGenreAliases map common spellings to one genre name, keys are compared without case
+var defaultGenreAliases = map[string]string{
+	"hip hop":        "Hip-Hop",
+	"hiphop":         "Hip-Hop",
+	"rnb":            "R&B",
+	"r'n'b":          "R&B",
+	"r and b":        "R&B",
+	"drum and bass":  "Drum & Bass",
+	"drum'n'bass":    "Drum & Bass",
+	"dnb":            "Drum & Bass",
+	"synth-pop":      "Synthpop",
+	"synth pop":      "Synthpop",
+	"rock and roll":  "Rock & Roll",
+	"rock 'n' roll":  "Rock & Roll",
+	"trip hop":       "Trip-Hop",
+	"electronica":    "Electronic",
+	"soundtracks":    "Soundtrack",
+	"original score": "Soundtrack",
+}
+
+func init() {
+	RegisterTrackRule(NewTrackRule("missing-genre", "Genre is empty.", SeverityWarning, missingGenreRule))
+	RegisterTrackRule(NewTrackRule("numeric-genre", "Genre is an unresolved ID3v1 genre code.", SeverityWarning, numericGenreRule))
+	RegisterTrackRule(NewTrackRuleWithOptions("genre-alias", "Genre is an alias of another genre name.", SeverityWarning, RuleOptions{"aliases": defaultGenreAliases}, genreAliasRule))
+	RegisterTrackRule(NewTrackRuleWithOptions("genre-not-allowed", "Genre is not in the allowed genres.", SeverityWarning, RuleOptions{"allowed": []string(nil), "aliases": defaultGenreAliases}, genreNotAllowedRule))
+
+	RegisterAlbumRule(NewAlbumRule("mixed-genres", "Directory tracks have different genres.", SeverityWarning, mixedGenresRule))
+}
+
+//splitGenres returns the genres of a multi-valued genre tag
+func splitGenres(s string) []string {
+	var genres []string
+	for _, genre := range strings.FieldsFunc(s, func(r rune) bool { return r == ';' || r == 0 }) {
+		if genre = strings.TrimSpace(genre); genre != "" {
+			genres = append(genres, genre)
+		}
+	}
+
+	return genres
+}
+
+//resolveNumericGenre returns the genre name of an ID3v1 code, ok is false when s holds no code
+func resolveNumericGenre(s string) (name string, ok bool) {
+	groups := numericGenreRegexp.FindStringSubmatch(s)
+	if groups == nil {
+		return "", false
+	}
+
+	//"(17)Rock" refines the code with a name
+	if refinement := strings.TrimSpace(groups[2]); refinement != "" {
+		return refinement, true
+	}
+
+	code, _ := strconv.Atoi(groups[1] + groups[3])
+	if code < len(id3v1Genres) {
+		return id3v1Genres[code], true
+	}
+
+	return "", true
+}
+
+//genreAliases returns the alias map of the rule options, keys lower cased
+func genreAliases(options RuleOptions) map[string]string {
+	configured, _ := options.StringMap("aliases", defaultGenreAliases)
+
+	aliases := map[string]string{}
+	for alias, genre := range configured {
+		aliases[strings.ToLower(alias)] = genre
+	}
+
+	return aliases
+}
+
+//canonicalGenre returns the genre name a genre should be written as: the resolved ID3v1 code,
+//the alias target, or the genre itself
+func canonicalGenre(genre string, aliases map[string]string) string {
+	if name, ok := resolveNumericGenre(genre); ok == true && name != "" {
+		genre = name
+	}
+
+	if target, exists := aliases[strings.ToLower(genre)]; exists == true {
+		return target
+	}
+
+	return genre
+}
+
+func missingGenreRule(track *TrackFile, options RuleOptions) []Finding {
+	if strings.TrimSpace(track.Genre()) != "" {
+		return nil
+	}
+
+	return []Finding{{Field: "genre", Value: track.Genre(), Message: "Genre is empty."}}
+}
+
+func numericGenreRule(track *TrackFile, options RuleOptions) []Finding {
+	var findings []Finding
+	for _, genre := range splitGenres(track.Genre()) {
+		name, ok := resolveNumericGenre(genre)
+		if ok == false {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Field:    "genre",
+			Value:    genre,
+			Expected: name,
+			Message:  fmt.Sprintf("Genre is an unresolved ID3v1 genre code (%s).", genre),
+		})
+	}
+
+	return findings
+}
+
+func genreAliasRule(track *TrackFile, options RuleOptions) []Finding {
+	aliases := genreAliases(options)
+
+	var findings []Finding
+	for _, genre := range splitGenres(track.Genre()) {
+		target, exists := aliases[strings.ToLower(genre)]
+		if exists == false || target == genre {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Field:    "genre",
+			Value:    genre,
+			Expected: target,
+			Message:  fmt.Sprintf("Genre %s should be written %s.", genre, target),
+		})
+	}
+
+	return findings
+}
+
+//genreNotAllowedRule stays silent until allowed genres are configured. Genres matching
+//an allowed one but for the case, through an alias or an ID3v1 code get it as suggested value.
+func genreNotAllowedRule(track *TrackFile, options RuleOptions) []Finding {
+	allowed, _ := options.Strings("allowed", nil)
+	if len(allowed) == 0 {
+		return nil
+	}
+	aliases := genreAliases(options)
+
+	var findings []Finding
+	for _, genre := range splitGenres(track.Genre()) {
+		if containsString(allowed, genre) == true {
+			continue
+		}
+
+		var expected string
+		canonical := canonicalGenre(genre, aliases)
+		for _, name := range allowed {
+			if strings.EqualFold(name, canonical) == true {
+				expected = name
+			}
+		}
+
+		findings = append(findings, Finding{
+			Field:    "genre",
+			Value:    genre,
+			Expected: expected,
+			Message:  fmt.Sprintf("Genre is not allowed (%s).", genre),
+		})
+	}
+
+	return findings
+}
+
+func mixedGenresRule(album *AlbumDir, options RuleOptions) []Finding {
+	counts := valueCounts{}
+	for _, track := range album.Tracks {
+		if genre := strings.TrimSpace(track.Genre()); genre != "" {
+			counts[genre]++
+		}
+	}
+
+	if len(counts) < 2 {
+		return nil
+	}
+
+	genres := counts.sorted()
+	sort.Strings(genres)
+
+	return []Finding{{
+		Field:    "genre",
+		Value:    strings.Join(genres, ", "),
+		Expected: counts.sorted()[0],
+		Message:  fmt.Sprintf("Directory contains multiple genres (%s).", counts.String()),
+	}}
+}
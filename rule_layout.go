@@ -0,0 +1,150 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+)
+
+func init() {
+	RegisterAlbumRule(&directoryLayoutRule{ignoreCase: true})
+}
+
+//directoryLayoutRule compares the trailing directories of an album path with its tags
+//through a layout such as "{album_artist}/{year} - {album}".
+//It stays silent until a layout is configured.
+type directoryLayoutRule struct {
+	layout     *namingTemplate
+	ignoreCase bool
+}
+
+func (r *directoryLayoutRule) ID() string { return "directory-layout" }
+
+func (r *directoryLayoutRule) Description() string {
+	return "Directory path does not match the tags through the directory layout."
+}
+
+func (r *directoryLayoutRule) Severity() Severity { return SeverityWarning }
+
+func (r *directoryLayoutRule) Configure(options RuleOptions) (Configurable, error) {
+	configured := *r
+
+	return &configured, configured.configure(options)
+}
+
+func (r *directoryLayoutRule) configure(options RuleOptions) error {
+	if err := options.checkKeys("layout", "ignore_case"); err != nil {
+		return err
+	}
+
+	ignoreCase, err := options.Bool("ignore_case", true)
+	if err != nil {
+		return err
+	}
+	r.ignoreCase = ignoreCase
+
+	pattern, err := options.String("layout", "")
+	if err != nil {
+		return err
+	}
+	pattern = strings.Trim(pattern, "/")
+	if pattern == "" {
+		r.layout = nil
+		return nil
+	}
+
+	t, err := parseNamingTemplate(pattern)
+	if err != nil {
+		return err
+	}
+	r.layout = t
+
+	return nil
+}
+
+func (r *directoryLayoutRule) Check(album *AlbumDir) []Finding {
+	if r.layout == nil || len(album.Tracks) == 0 {
+		return nil
+	}
+
+	values := albumTemplateValues(album)
+	components := strings.Split(filepath.ToSlash(filepath.Clean(album.Path)), "/")
+
+	//deepest layout first, so an optional disc directory is matched when present
+	for depth := r.layout.maxDepth; depth >= r.layout.minDepth; depth-- {
+		if depth > len(components) {
+			continue
+		}
+
+		dir := strings.Join(components[len(components)-depth:], "/")
+		matched, mismatches := r.layout.compare(dir, values, r.ignoreCase)
+		if matched == false {
+			continue
+		}
+
+		return r.mismatchFindings(album, dir, values, mismatches)
+	}
+
+	return []Finding{{
+		Field:    "directory",
+		Value:    album.Path,
+		Expected: r.layout.render(values),
+		Suspect:  SuspectFileName,
+		Message:  fmt.Sprintf("Directory does not follow the layout %s.", r.layout.pattern),
+	}}
+}
+
+func (r *directoryLayoutRule) mismatchFindings(album *AlbumDir, dir string, values map[string]string, mismatches []templateMismatch) []Finding {
+	var findings []Finding
+	for _, m := range mismatches {
+		if tagLooksWrong(album.library, m) == true {
+			findings = append(findings, Finding{
+				Field:    m.field,
+				Value:    m.tagValue,
+				Expected: m.nameValue,
+				Suspect:  SuspectTag,
+				Message:  fmt.Sprintf("Tag %s (%s) does not match the directory (%s).", m.field, m.tagValue, m.nameValue),
+			})
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Field:    "directory",
+			Value:    dir,
+			Expected: r.layout.render(values),
+			Suspect:  SuspectFileName,
+			Message:  fmt.Sprintf("Directory %s (%s) does not match the tag (%s).", m.field, m.nameValue, m.tagValue),
+		})
+	}
+
+	return findings
+}
+
+//albumTemplateValues returns the most common value of every placeholder among the album tracks
+func albumTemplateValues(album *AlbumDir) map[string]string {
+	counts := map[string]map[string]int{}
+	for _, track := range album.Tracks {
+		for field, value := range trackTemplateValues(track) {
+			if value == "" {
+				continue
+			}
+			if counts[field] == nil {
+				counts[field] = map[string]int{}
+			}
+			counts[field][value]++
+		}
+	}
+
+	values := map[string]string{}
+	for field, valueCounts := range counts {
+		var best string
+		for value, count := range valueCounts {
+			if best == "" || count > valueCounts[best] || (count == valueCounts[best] && value < best) {
+				best = value
+			}
+		}
+		values[field] = best
+	}
+
+	return values
+}
@@ -0,0 +1,216 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/dhowden/tag/mbz"
+	uuid "github.com/satori/go.uuid"
+)
+
+//mbidFields are the MusicBrainz identifiers checked, by export name
+var mbidFields = []struct {
+	name string
+	key  string
+}{
+	{"mb_recording_uuid", mbz.Recording},
+	{"mb_track_uuid", mbz.Track},
+	{"mb_album_uuid", mbz.Album},
+	{"mb_release_group_uuid", mbz.ReleaseGroup},
+	{"mb_artist_uuid", mbz.Artist},
+	{"mb_album_artist_uuid", mbz.AlbumArtist},
+}
+
+func init() {
+	RegisterTrackRule(NewTrackRule("invalid-mbid", "MusicBrainz identifier is not a valid UUID.", SeverityError, invalidMBIDRule))
+
+	RegisterAlbumRule(NewAlbumRule("multiple-album-mbids", "Directory tracks have different MusicBrainz album identifiers.", SeverityError, multipleAlbumMBIDsRule))
+	RegisterAlbumRule(NewAlbumRule("partial-mbids", "Only some tracks of the directory have MusicBrainz identifiers.", SeverityWarning, partialMBIDsRule))
+	RegisterAlbumRule(NewAlbumRule("duplicate-recording-mbid", "Directory contains the same MusicBrainz recording twice.", SeverityWarning, duplicateRecordingMBIDRule))
+
+	RegisterLibraryRule(NewLibraryRule("album-artist-mbid-names", "One MusicBrainz album artist identifier is used with several album artist names.", SeverityWarning, albumArtistMBIDNamesRule))
+	RegisterLibraryRule(NewLibraryRule("album-artist-name-mbids", "One album artist name is used with several MusicBrainz identifiers.", SeverityWarning, albumArtistNameMBIDsRule))
+}
+
+//splitMBIDs returns the identifiers of a multi-valued identifier tag, as artist ones are
+func splitMBIDs(s string) []string {
+	return strings.FieldsFunc(s, func(r rune) bool { return r == '/' || r == ';' || r == ',' || r == ' ' || r == 0 })
+}
+
+//hasMBIDs tells if a track holds any MusicBrainz identifier
+func hasMBIDs(track *TrackFile) bool {
+	info := track.MusicBrainz()
+	for _, field := range mbidFields {
+		if info.Get(field.key) != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func invalidMBIDRule(track *TrackFile, options RuleOptions) []Finding {
+	info := track.MusicBrainz()
+
+	var findings []Finding
+	for _, field := range mbidFields {
+		for _, id := range splitMBIDs(info.Get(field.key)) {
+			u, err := uuid.FromString(id)
+			if err == nil && u.String() == id {
+				continue
+			}
+
+			finding := Finding{
+				Field:   field.name,
+				Value:   id,
+				Message: fmt.Sprintf("MusicBrainz identifier %s is not a valid UUID (%s).", field.name, id),
+			}
+			//MusicBrainz writes identifiers lower case without braces
+			if err == nil {
+				finding.Expected = u.String()
+				finding.Message = fmt.Sprintf("MusicBrainz identifier %s is not in canonical form (%s).", field.name, id)
+			}
+
+			findings = append(findings, finding)
+		}
+	}
+
+	return findings
+}
+
+func multipleAlbumMBIDsRule(album *AlbumDir, options RuleOptions) []Finding {
+	counts := valueCounts{}
+	for _, track := range album.Tracks {
+		if id := track.MusicBrainz().Get(mbz.Album); id != "" {
+			counts[id]++
+		}
+	}
+
+	if len(counts) < 2 {
+		return nil
+	}
+
+	return []Finding{{
+		Field:    "mb_album_uuid",
+		Value:    counts.String(),
+		Expected: counts.sorted()[0],
+		Message:  fmt.Sprintf("Directory contains multiple MusicBrainz albums (%s).", counts.String()),
+	}}
+}
+
+func partialMBIDsRule(album *AlbumDir, options RuleOptions) []Finding {
+	var untagged []string
+	for _, track := range album.Tracks {
+		if hasMBIDs(track) == false {
+			untagged = append(untagged, track.Path)
+		}
+	}
+
+	if len(untagged) == 0 || len(untagged) == len(album.Tracks) {
+		return nil
+	}
+
+	var findings []Finding
+	for _, path := range untagged {
+		findings = append(findings, Finding{
+			Path:    path,
+			Field:   "mb_album_uuid",
+			Message: fmt.Sprintf("Track has no MusicBrainz identifier while %d of %d directory tracks have.", len(album.Tracks)-len(untagged), len(album.Tracks)),
+		})
+	}
+
+	return findings
+}
+
+func duplicateRecordingMBIDRule(album *AlbumDir, options RuleOptions) []Finding {
+	seen := map[string]*TrackFile{}
+
+	var findings []Finding
+	for _, track := range album.Tracks {
+		id := track.MusicBrainz().Get(mbz.Recording)
+		if id == "" {
+			continue
+		}
+
+		first, exists := seen[id]
+		if exists == false {
+			seen[id] = track
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Path:    track.Path,
+			Field:   "mb_recording_uuid",
+			Value:   id,
+			Message: fmt.Sprintf("MusicBrainz recording %s is also the one of %s.", id, first.Path),
+		})
+	}
+
+	return findings
+}
+
+//mappingFindings reports, for every key of the library used with several values,
+//each value but the most common one, on the first track holding it
+func mappingFindings(lib *Library, key func(*TrackFile) string, value func(*TrackFile) string, field string, message string) []Finding {
+	counts := map[string]valueCounts{}
+	firstTrack := map[string]*TrackFile{}
+
+	for _, track := range lib.Tracks {
+		k, v := key(track), value(track)
+		if k == "" || v == "" {
+			continue
+		}
+
+		if counts[k] == nil {
+			counts[k] = valueCounts{}
+		}
+		counts[k][v]++
+
+		if firstTrack[k+"\x00"+v] == nil {
+			firstTrack[k+"\x00"+v] = track
+		}
+	}
+
+	var keys []string
+	for k, values := range counts {
+		if len(values) > 1 {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	var findings []Finding
+	for _, k := range keys {
+		values := counts[k].sorted()
+		for _, v := range values[1:] {
+			findings = append(findings, Finding{
+				Path:     firstTrack[k+"\x00"+v].Path,
+				Field:    field,
+				Value:    v,
+				Expected: values[0],
+				Message:  fmt.Sprintf(message, k, counts[k].String()),
+			})
+		}
+	}
+
+	return findings
+}
+
+func albumArtistMBID(track *TrackFile) string {
+	return track.MusicBrainz().Get(mbz.AlbumArtist)
+}
+
+func albumArtistName(track *TrackFile) string {
+	return track.AlbumArtist()
+}
+
+func albumArtistMBIDNamesRule(lib *Library, options RuleOptions) []Finding {
+	return mappingFindings(lib, albumArtistMBID, albumArtistName, "album_artist",
+		"MusicBrainz album artist %s is used with several names (%s).")
+}
+
+func albumArtistNameMBIDsRule(lib *Library, options RuleOptions) []Finding {
+	return mappingFindings(lib, albumArtistName, albumArtistMBID, "mb_album_artist_uuid",
+		"Album artist %s is used with several MusicBrainz identifiers (%s).")
+}
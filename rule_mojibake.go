@@ -0,0 +1,160 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/encoding/charmap"
+	"golang.org/x/text/encoding/japanese"
+)
+
+func init() {
+	RegisterTrackRule(NewTrackRule("mojibake", "Tag looks like text decoded with the wrong encoding.", SeverityWarning, mojibakeRule))
+}
+
+func mojibakeRule(track *TrackFile, options RuleOptions) []Finding {
+	var findings []Finding
+	for _, field := range []string{"title", "artist", "album", "album_artist"} {
+		value := tagField(track, field)
+
+		encoding, decoded := detectMojibake(value)
+		if encoding == "" {
+			continue
+		}
+
+		findings = append(findings, Finding{
+			Field:    field,
+			Value:    value,
+			Expected: decoded,
+			Message:  fmt.Sprintf("%s looks like %s text read as Latin-1 (%s, probably %s).", fieldLabel(field), encoding, value, decoded),
+		})
+	}
+
+	return findings
+}
+
+//detectMojibake returns the probable original encoding of s and s decoded with it,
+//or an empty encoding when s looks right. Text stored as Latin-1 or Windows-1252
+//is turned back into its bytes, which are then decoded as UTF-8, Windows-1251 or Shift-JIS.
+func detectMojibake(s string) (string, string) {
+	raw, ok := latin1Bytes(s)
+	if ok == false {
+		return "", ""
+	}
+
+	//a valid multi-byte UTF-8 sequence seldom happens by chance in Latin-1 text
+	if utf8.Valid(raw) == true && utf8.RuneCount(raw) < len(raw) {
+		return "UTF-8", string(raw)
+	}
+
+	//a real Latin-1 text is mostly ASCII letters with a few accented ones
+	if latin1Implausible(s) == false {
+		return "", ""
+	}
+
+	if decoded, err := charmap.Windows1251.NewDecoder().Bytes(raw); err == nil && plausibleCyrillic(string(decoded)) == true {
+		return "CP1251", string(decoded)
+	}
+
+	if decoded, err := japanese.ShiftJIS.NewDecoder().Bytes(raw); err == nil && plausibleJapanese(string(decoded)) == true {
+		return "Shift-JIS", string(decoded)
+	}
+
+	return "", ""
+}
+
+//latin1Bytes returns the bytes s was decoded from as Latin-1, or as Windows-1252 when s holds
+//characters of that code page; ok is false for pure ASCII or text no single byte code page gives
+func latin1Bytes(s string) ([]byte, bool) {
+	var raw []byte
+	var highBytes = 0
+	for _, r := range s {
+		if r > 0xff {
+			encoded, err := charmap.Windows1252.NewEncoder().String(s)
+			if err != nil {
+				return nil, false
+			}
+			return []byte(encoded), true
+		}
+
+		if r >= 0x80 {
+			highBytes++
+		}
+		raw = append(raw, byte(r))
+	}
+
+	return raw, highBytes > 0
+}
+
+//latin1Implausible tells if s holds more non-ASCII characters than ASCII letters,
+//or C1 control characters that Latin-1 text never holds
+func latin1Implausible(s string) bool {
+	var asciiLetters, high = 0, 0
+	for _, r := range s {
+		switch {
+		case r >= 0x80 && r <= 0x9f:
+			return true
+		case r >= 0x80:
+			high++
+		case r < 0x80 && unicode.IsLetter(r):
+			asciiLetters++
+		}
+	}
+
+	return high >= 3 && high > asciiLetters
+}
+
+//plausibleCyrillic tells if the non-ASCII letters of s are Cyrillic text with a sensible case:
+//no upper case letter after a lower case one inside a word, some lower case letters and at
+//least three different letters. Accented Latin capitals map one to one to Cyrillic capitals,
+//so all-caps names such as "ÅÄÖ" or "ÉÉÉ" would otherwise read as "ЕДЦ" or "ЙЙЙ".
+func plausibleCyrillic(s string) bool {
+	var letters, cyrillic, lower = 0, 0, 0
+	distinct := map[rune]bool{}
+	for _, word := range strings.Fields(s) {
+		if mixedCase(word) == true {
+			return false
+		}
+
+		for _, r := range word {
+			if r < 0x80 || unicode.IsLetter(r) == false {
+				continue
+			}
+
+			letters++
+			if unicode.Is(unicode.Cyrillic, r) {
+				cyrillic++
+				distinct[unicode.ToLower(r)] = true
+				if unicode.IsLower(r) == true {
+					lower++
+				}
+			}
+		}
+	}
+
+	return letters > 0 && cyrillic*10 >= letters*9 && lower > 0 && len(distinct) >= 3
+}
+
+//plausibleJapanese tells if s holds Japanese text: kana or kanji for most non-ASCII characters
+//and no half-width katakana, which Latin text decoded as Shift-JIS is full of
+func plausibleJapanese(s string) bool {
+	var nonASCII, japanese = 0, 0
+	for _, r := range s {
+		if r == utf8.RuneError || (r >= 0xff61 && r <= 0xff9f) {
+			return false
+		}
+
+		if r < 0x80 {
+			continue
+		}
+
+		nonASCII++
+		if unicode.In(r, unicode.Hiragana, unicode.Katakana, unicode.Han) || (r >= 0x3000 && r <= 0x303f) || (r >= 0xff01 && r <= 0xff5e) {
+			japanese++
+		}
+	}
+
+	return japanese > 0 && japanese*2 >= nonASCII
+}
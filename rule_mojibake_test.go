@@ -0,0 +1,41 @@
+package main
+
+import "testing"
+
+func TestDetectMojibake(t *testing.T) {
+	tests := []struct {
+		value    string
+		encoding string
+		decoded  string
+	}{
+		{"Ã©tÃ©", "UTF-8", "été"},
+		{"Ð¡Ð»Ð°Ð²Ð°", "UTF-8", "Слава"},
+		{"Ñëàâà", "CP1251", "Слава"},
+		{"ïðèâåò ìèð", "CP1251", "привет мир"},
+		{"Êèíî", "CP1251", "Кино"},
+		{"“Œ‹žŽ–•Ï", "Shift-JIS", "東京事変"},
+		//Latin-1 text stays as it is, all-caps names included
+		{"ÅÄÖ", "", ""},
+		{"ÉÉÉ", "", ""},
+		{"ÆØÅ", "", ""},
+		{"ÄÖÜ", "", ""},
+		{"ÉLÉGIE", "", ""},
+		{"ÇA ÉTÉ", "", ""},
+		{"Björk", "", ""},
+		{"Mötley Crüe", "", ""},
+		{"Sigur Rós", "", ""},
+		{"Hörspiel für Kinder", "", ""},
+		{"Déjà vu", "", ""},
+		{"Les Misérables", "", ""},
+		{"Ólafur Arnalds", "", ""},
+		{"Plain ASCII", "", ""},
+		{"", "", ""},
+	}
+
+	for _, test := range tests {
+		encoding, decoded := detectMojibake(test.value)
+		if encoding != test.encoding || decoded != test.decoded {
+			t.Errorf("detectMojibake(%q) = %q, %q, want %q, %q", test.value, encoding, decoded, test.encoding, test.decoded)
+		}
+	}
+}
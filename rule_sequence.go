@@ -0,0 +1,239 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	RegisterAlbumRule(NewAlbumRule("track-gap", "Directory track numbers have gaps.", SeverityWarning, trackGapRule))
+	RegisterAlbumRule(NewAlbumRule("track-above-total", "Track number is above the track total.", SeverityError, trackAboveTotalRule))
+	RegisterAlbumRule(NewAlbumRule("missing-first-track", "Directory has no track 1.", SeverityWarning, missingFirstTrackRule))
+	RegisterAlbumRule(NewAlbumRule("track-total-mismatch", "Directory tracks disagree on the track total.", SeverityWarning, trackTotalMismatchRule))
+	RegisterAlbumRule(NewAlbumRule("missing-disc", "Discs of a multi-disc set are missing.", SeverityWarning, missingDiscRule))
+}
+
+//tracksByDisc groups numbered tracks by disc number and returns the sorted disc numbers
+func tracksByDisc(tracks []*TrackFile) ([]int, map[int][]*TrackFile) {
+	discs := map[int][]*TrackFile{}
+	for _, track := range tracks {
+		number, _ := track.Track()
+		if number <= 0 {
+			continue
+		}
+
+		disc, _ := track.Disc()
+		discs[disc] = append(discs[disc], track)
+	}
+
+	var numbers []int
+	for disc := range discs {
+		numbers = append(numbers, disc)
+	}
+	sort.Ints(numbers)
+
+	return numbers, discs
+}
+
+func discLabel(disc int) string {
+	if disc <= 0 {
+		return ""
+	}
+
+	return fmt.Sprintf(" on disc %d", disc)
+}
+
+func joinInts(numbers []int) string {
+	var s []string
+	for _, n := range numbers {
+		s = append(s, strconv.Itoa(n))
+	}
+
+	return strings.Join(s, ", ")
+}
+
+//joinRanges joins sorted numbers, collapsing runs into ranges: "2-5, 7"
+func joinRanges(numbers []int) string {
+	var s []string
+	for i := 0; i < len(numbers); i++ {
+		start := numbers[i]
+		for i+1 < len(numbers) && numbers[i+1] == numbers[i]+1 {
+			i++
+		}
+
+		switch {
+		case numbers[i] == start:
+			s = append(s, strconv.Itoa(start))
+		case numbers[i] == start+1:
+			s = append(s, strconv.Itoa(start), strconv.Itoa(numbers[i]))
+		default:
+			s = append(s, fmt.Sprintf("%d-%d", start, numbers[i]))
+		}
+	}
+
+	return strings.Join(s, ", ")
+}
+
+//trackGapRule reports numbers missing between the lowest and the highest track of
+//each disc; track totals are left to the track-above-total and track-total-mismatch rules
+func trackGapRule(album *AlbumDir, options RuleOptions) []Finding {
+	var findings []Finding
+
+	discs, tracks := tracksByDisc(album.Tracks)
+	for _, disc := range discs {
+		present := map[int]bool{}
+		first, last := 0, 0
+		for _, track := range tracks[disc] {
+			number, _ := track.Track()
+			present[number] = true
+			if first == 0 || number < first {
+				first = number
+			}
+			if number > last {
+				last = number
+			}
+		}
+
+		var missing []int
+		for n := first; n <= last; n++ {
+			if present[n] == false {
+				missing = append(missing, n)
+			}
+		}
+
+		if len(missing) > 0 {
+			findings = append(findings, Finding{
+				Field:   "track",
+				Value:   joinRanges(missing),
+				Message: fmt.Sprintf("Directory is missing tracks %s%s.", joinRanges(missing), discLabel(disc)),
+			})
+		}
+	}
+
+	return findings
+}
+
+func trackAboveTotalRule(album *AlbumDir, options RuleOptions) []Finding {
+	var findings []Finding
+	for _, track := range album.Tracks {
+		number, total := track.Track()
+		if total > 0 && number > total {
+			findings = append(findings, Finding{
+				Path:     track.Path,
+				Field:    "track",
+				Value:    strconv.Itoa(number),
+				Expected: fmt.Sprintf("<= %d", total),
+				Message:  fmt.Sprintf("Track number %d is above the track total %d.", number, total),
+			})
+		}
+	}
+
+	return findings
+}
+
+func missingFirstTrackRule(album *AlbumDir, options RuleOptions) []Finding {
+	var findings []Finding
+
+	discs, tracks := tracksByDisc(album.Tracks)
+	for _, disc := range discs {
+		var hasFirst = false
+		for _, track := range tracks[disc] {
+			if number, _ := track.Track(); number == 1 {
+				hasFirst = true
+			}
+		}
+
+		if hasFirst == false {
+			findings = append(findings, Finding{
+				Field:    "track",
+				Expected: "1",
+				Message:  fmt.Sprintf("Directory has no track 1%s.", discLabel(disc)),
+			})
+		}
+	}
+
+	return findings
+}
+
+func trackTotalMismatchRule(album *AlbumDir, options RuleOptions) []Finding {
+	var findings []Finding
+
+	discs, tracks := tracksByDisc(album.Tracks)
+	for _, disc := range discs {
+		var totals []int
+		for _, track := range tracks[disc] {
+			if _, total := track.Track(); total > 0 && containsInt(totals, total) == false {
+				totals = append(totals, total)
+			}
+		}
+
+		if len(totals) > 1 {
+			sort.Ints(totals)
+			findings = append(findings, Finding{
+				Field:   "track_total",
+				Value:   joinInts(totals),
+				Message: fmt.Sprintf("Directory tracks have different track totals%s (%s).", discLabel(disc), joinInts(totals)),
+			})
+		}
+	}
+
+	return findings
+}
+
+//missingDiscRule looks at every directory of a release, as discs are often split
+//into sub directories, and reports once on the first directory of the release
+func missingDiscRule(album *AlbumDir, options RuleOptions) []Finding {
+	dirs := album.ReleaseDirs()
+	if dirs[0] != album {
+		return nil
+	}
+
+	present := map[int]bool{}
+	last := 0
+	for _, dir := range dirs {
+		for _, track := range dir.Tracks {
+			disc, total := track.Disc()
+			if disc > 0 {
+				present[disc] = true
+			}
+			if disc > last {
+				last = disc
+			}
+			if total > last {
+				last = total
+			}
+		}
+	}
+
+	if last < 2 {
+		return nil
+	}
+
+	var missing []int
+	for n := 1; n <= last; n++ {
+		if present[n] == false {
+			missing = append(missing, n)
+		}
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+
+	return []Finding{{
+		Field:   "disc",
+		Value:   joinRanges(missing),
+		Message: fmt.Sprintf("Missing discs %s out of %d.", joinRanges(missing), last),
+	}}
+}
+
+func containsInt(s []int, e int) bool {
+	for _, a := range s {
+		if a == e {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,212 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+//suspiciousDictionary holds the placeholder patterns of a language. Words match as whole
+//words anywhere in a value, strings match the whole value. In both, spaces also match
+//"_", "-", "." or nothing, and "#" matches a number: "track #" matches "Track 07" and "Track_7".
+type suspiciousDictionary struct {
+	words   []string
+	strings []string
+}
+
+//defaultSuspiciousDictionaries are the built-in placeholder patterns by language
+var defaultSuspiciousDictionaries = map[string]suspiciousDictionary{
+	"en": {
+		words:   []string{"unknow", "untitled", "unknown artist", "unknown album"},
+		strings: []string{"unknown", "unknown title", "unknown track", "no title", "track #", "track", "audio track #", "new album", "album #"},
+	},
+	"fr": {
+		words:   []string{"sans titre", "artiste inconnu", "album inconnu"},
+		strings: []string{"inconnu", "titre #", "piste #", "plage #"},
+	},
+	"de": {
+		words:   []string{"unbekannter künstler", "unbekanntes album", "ohne titel"},
+		strings: []string{"unbekannt", "titel #", "spur #"},
+	},
+	"es": {
+		words:   []string{"artista desconocido", "álbum desconocido", "sin título"},
+		strings: []string{"desconocido", "pista #"},
+	},
+	"it": {
+		words:   []string{"artista sconosciuto", "album sconosciuto", "senza titolo"},
+		strings: []string{"sconosciuto", "traccia #", "brano #"},
+	},
+	"pt": {
+		words:   []string{"artista desconhecido", "álbum desconhecido", "sem título"},
+		strings: []string{"desconhecido", "faixa #"},
+	},
+	"ru": {
+		words:   []string{"неизвестный исполнитель", "неизвестный альбом", "без названия"},
+		strings: []string{"неизвестно", "трек #", "дорожка #"},
+	},
+	"ja": {
+		words:   []string{"不明なアーティスト", "不明なアルバム"},
+		strings: []string{"トラック#", "トラック #"},
+	},
+}
+
+func init() {
+	RegisterTrackRule(newSuspiciousRule("unknown-title", "Track title contains a suspicious word.", "title"))
+	RegisterTrackRule(newSuspiciousRule("unknown-album", "Album name contains a suspicious word.", "album"))
+	RegisterTrackRule(newSuspiciousRule("unknown-album-artist", "Album artist name contains a suspicious word.", "album_artist"))
+	RegisterTrackRule(newSuspiciousRule("unknown-artist", "Artist name contains a suspicious word.", "artist"))
+}
+
+//suspiciousPattern is a compiled dictionary pattern
+type suspiciousPattern struct {
+	pattern string
+	regexp  *regexp.Regexp
+}
+
+//suspiciousMatcher finds placeholder values through dictionary patterns
+type suspiciousMatcher []suspiciousPattern
+
+//defaultSuspiciousMatcher holds the patterns of every built-in language
+var defaultSuspiciousMatcher = newSuspiciousMatcher(suspiciousLanguages(), nil, nil)
+
+//suspiciousLanguages returns the built-in languages
+func suspiciousLanguages() []string {
+	var languages []string
+	for language := range defaultSuspiciousDictionaries {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+
+	return languages
+}
+
+//newSuspiciousMatcher compiles the dictionaries of languages plus extra words and strings
+func newSuspiciousMatcher(languages []string, words []string, wholeStrings []string) suspiciousMatcher {
+	for _, language := range languages {
+		words = append(words, defaultSuspiciousDictionaries[language].words...)
+		wholeStrings = append(wholeStrings, defaultSuspiciousDictionaries[language].strings...)
+	}
+
+	var m suspiciousMatcher
+	for _, word := range words {
+		m = append(m, suspiciousPattern{word, regexp.MustCompile(`(?i)(?:^|[^\p{L}\p{N}])` + patternRegexp(word) + `(?:[^\p{L}\p{N}]|$)`)})
+	}
+	for _, s := range wholeStrings {
+		m = append(m, suspiciousPattern{s, regexp.MustCompile(`(?i)^\s*` + patternRegexp(s) + `\s*$`)})
+	}
+
+	return m
+}
+
+//patternRegexp quotes a dictionary pattern, spaces and "#" aside
+func patternRegexp(pattern string) string {
+	var parts []string
+	for _, word := range strings.Fields(pattern) {
+		parts = append(parts, strings.Replace(regexp.QuoteMeta(word), "#", `#?\s*\d+`, -1))
+	}
+
+	return strings.Join(parts, `[\s_.\-]*`)
+}
+
+//match returns the first pattern s matches, or an empty string
+func (m suspiciousMatcher) match(s string) string {
+	for _, p := range m {
+		if p.regexp.MatchString(s) == true {
+			return p.pattern
+		}
+	}
+
+	return ""
+}
+
+//suspiciousMatchers returns the matchers of the suspicious rules among rules by field
+func suspiciousMatchers(rules []TrackRule) map[string]suspiciousMatcher {
+	matchers := map[string]suspiciousMatcher{}
+	for _, rule := range rules {
+		if configured, ok := rule.(configuredTrackRule); ok == true {
+			rule = configured.TrackRule
+		}
+
+		if r, ok := rule.(*suspiciousRule); ok == true {
+			matchers[r.field] = r.matcher
+		}
+	}
+
+	return matchers
+}
+
+//suspiciousRule checks a tag field against the placeholder dictionary
+type suspiciousRule struct {
+	id          string
+	description string
+	field       string
+	matcher     suspiciousMatcher
+}
+
+func newSuspiciousRule(id string, description string, field string) *suspiciousRule {
+	r := &suspiciousRule{id: id, description: description, field: field}
+	r.configure(RuleOptions{})
+
+	return r
+}
+
+func (r *suspiciousRule) ID() string { return r.id }
+
+func (r *suspiciousRule) Description() string { return r.description }
+
+func (r *suspiciousRule) Severity() Severity { return SeverityWarning }
+
+func (r *suspiciousRule) Configure(options RuleOptions) (Configurable, error) {
+	configured := *r
+
+	return &configured, configured.configure(options)
+}
+
+//configure selects the built-in languages, all by default, and adds the words and
+//strings patterns; "languages: []" keeps the configured patterns only
+func (r *suspiciousRule) configure(options RuleOptions) error {
+	if err := options.checkKeys("languages", "words", "strings"); err != nil {
+		return err
+	}
+
+	languages, err := options.Strings("languages", suspiciousLanguages())
+	if err != nil {
+		return err
+	}
+	for _, language := range languages {
+		if _, exists := defaultSuspiciousDictionaries[language]; exists == false {
+			return fmt.Errorf("unknown language %q, expected one of %s", language, strings.Join(suspiciousLanguages(), ", "))
+		}
+	}
+
+	words, err := options.Strings("words", nil)
+	if err != nil {
+		return err
+	}
+
+	wholeStrings, err := options.Strings("strings", nil)
+	if err != nil {
+		return err
+	}
+
+	r.matcher = newSuspiciousMatcher(languages, words, wholeStrings)
+
+	return nil
+}
+
+func (r *suspiciousRule) Check(track *TrackFile) []Finding {
+	value := tagField(track, r.field)
+
+	pattern := r.matcher.match(value)
+	if pattern == "" {
+		return nil
+	}
+
+	return []Finding{{
+		Field:   r.field,
+		Value:   value,
+		Pattern: pattern,
+		Message: fmt.Sprintf("%s looks like a placeholder, it matches %q (%s).", fieldLabel(r.field), pattern, value),
+	}}
+}
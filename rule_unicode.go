@@ -0,0 +1,220 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//textTagFields are the free text tags checked for stray characters
+var textTagFields = []string{"title", "album", "artist", "album_artist", "composer", "genre"}
+
+//zeroWidthChars are invisible characters that make equal looking strings differ
+const zeroWidthChars = "\u200b\u200c\u200d\u2060\ufeff\u00ad"
+
+var doubledSpaceReplacer = strings.NewReplacer("  ", " ")
+
+func init() {
+	RegisterTrackRule(NewTrackRule("untrimmed-whitespace", "Tag starts or ends with whitespace.", SeverityWarning, untrimmedWhitespaceRule))
+	RegisterTrackRule(NewTrackRule("doubled-space", "Tag contains doubled spaces.", SeverityWarning, doubledSpaceRule))
+	RegisterTrackRule(NewTrackRule("control-characters", "Tag contains control characters.", SeverityError, controlCharactersRule))
+	RegisterTrackRule(NewTrackRule("zero-width-characters", "Tag contains zero-width characters.", SeverityWarning, zeroWidthCharactersRule))
+	RegisterTrackRule(NewTrackRule("non-nfc", "Tag or file name is not NFC normalized.", SeverityWarning, nonNFCRule))
+
+	RegisterAlbumRule(NewAlbumRule("non-nfc-directory", "Directory name is not NFC normalized.", SeverityWarning, nonNFCDirectoryRule))
+
+	RegisterLibraryRule(NewLibraryRuleWithOptions("normalization-duplicates", "Tag values differ only by whitespace, invisible characters or Unicode normalization.", SeverityWarning, RuleOptions{"ignore_case": false, "fields": normalizationFields}, normalizationDuplicatesRule))
+}
+
+//textFieldFindings reports every text tag whose value fix changes
+func textFieldFindings(track *TrackFile, message string, fix func(string) string) []Finding {
+	var findings []Finding
+	for _, field := range textTagFields {
+		value := tagField(track, field)
+		if expected := fix(value); expected != value {
+			findings = append(findings, Finding{
+				Field:    field,
+				Value:    value,
+				Expected: expected,
+				Message:  fmt.Sprintf(message, fieldLabel(field), value),
+			})
+		}
+	}
+
+	return findings
+}
+
+func untrimmedWhitespaceRule(track *TrackFile, options RuleOptions) []Finding {
+	return textFieldFindings(track, "%s starts or ends with whitespace (%q).", strings.TrimSpace)
+}
+
+func doubledSpaceRule(track *TrackFile, options RuleOptions) []Finding {
+	return textFieldFindings(track, "%s contains doubled spaces (%q).", collapseSpaces)
+}
+
+func controlCharactersRule(track *TrackFile, options RuleOptions) []Finding {
+	return textFieldFindings(track, "%s contains control characters (%q).", removeControlChars)
+}
+
+func zeroWidthCharactersRule(track *TrackFile, options RuleOptions) []Finding {
+	return textFieldFindings(track, "%s contains zero-width characters (%q).", removeZeroWidthChars)
+}
+
+func nonNFCRule(track *TrackFile, options RuleOptions) []Finding {
+	findings := textFieldFindings(track, "%s is not NFC normalized (%+q).", norm.NFC.String)
+
+	name := filepath.Base(track.Path)
+	if norm.NFC.IsNormalString(name) == false {
+		findings = append(findings, Finding{
+			Field:    "filename",
+			Value:    name,
+			Expected: norm.NFC.String(name),
+			Message:  fmt.Sprintf("File name is not NFC normalized (%+q).", name),
+		})
+	}
+
+	return findings
+}
+
+func nonNFCDirectoryRule(album *AlbumDir, options RuleOptions) []Finding {
+	name := filepath.Base(album.Path)
+	if norm.NFC.IsNormalString(name) == true {
+		return nil
+	}
+
+	return []Finding{{
+		Field:    "directory",
+		Value:    name,
+		Expected: norm.NFC.String(name),
+		Message:  fmt.Sprintf("Directory name is not NFC normalized (%+q).", name),
+	}}
+}
+
+func collapseSpaces(s string) string {
+	for strings.Contains(s, "  ") {
+		s = doubledSpaceReplacer.Replace(s)
+	}
+
+	return s
+}
+
+func removeControlChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+func removeZeroWidthChars(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(zeroWidthChars, r) {
+			return -1
+		}
+		return r
+	}, s)
+}
+
+//normalizeText folds what makes equal looking strings differ: compatibility forms,
+//invisible characters and whitespace
+func normalizeText(s string) string {
+	s = norm.NFKC.String(s)
+	s = removeZeroWidthChars(removeControlChars(s))
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+//normalizationFields are the fields compared by default by normalizationDuplicatesRule
+var normalizationFields = []string{"artist", "album_artist", "album", "genre"}
+
+//normalizationDuplicatesRule reports tag values that only differ from a more common
+//value of the library by normalization, once per value on its first track
+func normalizationDuplicatesRule(lib *Library, options RuleOptions) []Finding {
+	ignoreCase, _ := options.Bool("ignore_case", false)
+	fields, _ := options.Strings("fields", normalizationFields)
+
+	var findings []Finding
+	for _, field := range fields {
+		counts := map[string]map[string]int{}
+		firstTrack := map[string]*TrackFile{}
+
+		for _, track := range lib.Tracks {
+			value := tagField(track, field)
+			if value == "" {
+				continue
+			}
+
+			key := normalizeText(value)
+			if ignoreCase == true {
+				key = strings.ToLower(key)
+			}
+
+			if counts[key] == nil {
+				counts[key] = map[string]int{}
+			}
+			counts[key][value]++
+
+			if firstTrack[value] == nil {
+				firstTrack[value] = track
+			}
+		}
+
+		var keys []string
+		for key, variants := range counts {
+			if len(variants) > 1 {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, key := range keys {
+			variants := counts[key]
+
+			var values []string
+			var mostCommon string
+			for value, count := range variants {
+				values = append(values, value)
+				if mostCommon == "" || preferVariant(value, count, mostCommon, variants[mostCommon]) == true {
+					mostCommon = value
+				}
+			}
+			sort.Strings(values)
+
+			for _, value := range values {
+				if value == mostCommon {
+					continue
+				}
+
+				findings = append(findings, Finding{
+					Path:     firstTrack[value].Path,
+					Field:    field,
+					Value:    value,
+					Expected: mostCommon,
+					Message:  fmt.Sprintf("%s %+q differs from %+q only by normalization (%d tracks).", fieldLabel(field), value, mostCommon, variants[value]),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+//preferVariant tells if value is a better reference than current: more common,
+//then already normalized, then first in order
+func preferVariant(value string, count int, current string, currentCount int) bool {
+	if count != currentCount {
+		return count > currentCount
+	}
+
+	normalized, currentNormalized := value == normalizeText(value), current == normalizeText(current)
+	if normalized != currentNormalized {
+		return normalized
+	}
+
+	return value < current
+}
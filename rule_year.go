@@ -0,0 +1,173 @@
+package main
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+
+	tag "github.com/dhowden/tag"
+)
+
+//first sound recording, default minimum year
+const firstRecordingYear = 1877
+
+//dateTags are raw tags holding an ISO 8601 date or a year, by preference order
+var dateTags = []string{
+	"TDRC", "TDRL", "TDOR", //ID3v2.4
+	"TYER", "TORY", //ID3v2.3
+	"TYE", "TOR", //ID3v2.2
+	"date", "originaldate", //Vorbis comments
+	"year", "\xa9day", //MP4 and ID3v1
+}
+
+var isoDateRegexp = regexp.MustCompile(`^\d{4}(-\d{2}(-\d{2}([T ]\d{2}(:\d{2}(:\d{2})?)?Z?)?)?)?$`)
+
+var isoDateLayouts = []string{"2006", "2006-01", "2006-01-02"}
+
+func init() {
+	RegisterTrackRule(NewTrackRuleWithOptions("invalid-year", "Year is missing, before the first recordings or in the future.", SeverityWarning, RuleOptions{"min_year": firstRecordingYear}, invalidYearRule))
+	RegisterTrackRule(NewTrackRule("malformed-date", "Date tag is not an ISO 8601 date.", SeverityWarning, malformedDateRule))
+
+	RegisterAlbumRule(NewAlbumRule("year-mismatch", "Directory tracks have different years.", SeverityWarning, yearMismatchRule))
+}
+
+//validDate tells if a raw date tag holds a real ISO 8601 date, as "2004", "2004-05" or "2004-05-17T20:00"
+func validDate(s string) bool {
+	if isoDateRegexp.MatchString(s) == false {
+		return false
+	}
+
+	datePart := s
+	if len(datePart) > 10 {
+		datePart = datePart[:10]
+	}
+
+	_, err := time.Parse(isoDateLayouts[(len(datePart)-4)/3], datePart)
+
+	return err == nil
+}
+
+//validDDMM tells if an ID3v2.3 TDAT tag holds a day and month
+func validDDMM(s string) bool {
+	if len(s) != 4 {
+		return false
+	}
+
+	_, err := time.Parse("0201", s)
+
+	return err == nil
+}
+
+//releaseDate returns the most precise valid release date of the raw tags as YYYY[-MM[-DD]]
+func releaseDate(m tag.Metadata) string {
+	raw := m.Raw()
+
+	var best string
+	for _, key := range dateTags {
+		s, _ := raw[key].(string)
+		if s == "" || validDate(s) == false {
+			continue
+		}
+
+		if len(s) > 10 {
+			s = s[:10]
+		}
+
+		//ID3v2.3 keeps day and month apart from the year
+		if key == "TYER" {
+			if ddmm, _ := raw["TDAT"].(string); validDDMM(ddmm) == true {
+				s = s + "-" + ddmm[2:] + "-" + ddmm[:2]
+			}
+		}
+
+		if len(s) > len(best) {
+			best = s
+		}
+	}
+
+	return best
+}
+
+func invalidYearRule(track *TrackFile, options RuleOptions) []Finding {
+	year := track.Year()
+	minYear, _ := options.Int("min_year", firstRecordingYear)
+	maxYear := time.Now().Year()
+
+	if year == 0 {
+		return []Finding{{Field: "year", Value: "0", Message: "Year is empty."}}
+	}
+
+	if year < minYear || year > maxYear {
+		return []Finding{{
+			Field:    "year",
+			Value:    strconv.Itoa(year),
+			Expected: fmt.Sprintf("%d-%d", minYear, maxYear),
+			Message:  fmt.Sprintf("Year is impossible (%d).", year),
+		}}
+	}
+
+	return nil
+}
+
+func malformedDateRule(track *TrackFile, options RuleOptions) []Finding {
+	var findings []Finding
+
+	raw := track.Raw()
+	for _, key := range append([]string{"TDAT"}, dateTags...) {
+		s, ok := raw[key].(string)
+		if ok == false || s == "" {
+			continue
+		}
+
+		valid := validDate(s)
+		switch key {
+		case "TDAT":
+			valid = validDDMM(s)
+		case "TYER", "TORY", "TYE", "TOR":
+			valid = validDate(s) && len(s) == 4
+		}
+
+		if valid == false {
+			//the raw tag name only shows up in the message, fields are the same for every format
+			findings = append(findings, Finding{
+				Field:   "date",
+				Value:   s,
+				Message: fmt.Sprintf("Date tag %s is malformed (%s).", key, s),
+			})
+		}
+	}
+
+	return findings
+}
+
+func yearMismatchRule(album *AlbumDir, options RuleOptions) []Finding {
+	counts := map[int]int{}
+	for _, track := range album.Tracks {
+		if year := track.Year(); year != 0 {
+			counts[year]++
+		}
+	}
+
+	if len(counts) < 2 {
+		return nil
+	}
+
+	var years []int
+	var mostCommon = 0
+	for year, count := range counts {
+		years = append(years, year)
+		if mostCommon == 0 || count > counts[mostCommon] || (count == counts[mostCommon] && year < mostCommon) {
+			mostCommon = year
+		}
+	}
+	sort.Ints(years)
+
+	return []Finding{{
+		Field:    "year",
+		Value:    joinInts(years),
+		Expected: strconv.Itoa(mostCommon),
+		Message:  fmt.Sprintf("Directory contains multiple years (%s).", joinInts(years)),
+	}}
+}
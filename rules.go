@@ -0,0 +1,277 @@
+package main
+
+import (
+	"fmt"
+)
+
+//Severity of a rule
+type Severity int
+
+const (
+	//SeverityWarning reports a suspicious tag
+	SeverityWarning Severity = iota + 1
+	//SeverityError reports a broken tag
+	SeverityError
+)
+
+func (s Severity) String() string {
+	switch s {
+	case SeverityError:
+		return "error"
+	case SeverityWarning:
+		return "warning"
+	}
+	return "unknown"
+}
+
+//MarshalText encodes the severity as its name
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+//UnmarshalText decodes a severity name
+func (s *Severity) UnmarshalText(text []byte) error {
+	severity, err := parseSeverity(string(text))
+	if err != nil {
+		return err
+	}
+
+	*s = severity
+
+	return nil
+}
+
+func parseSeverity(s string) (Severity, error) {
+	switch sanitizeString(s) {
+	case "error":
+		return SeverityError, nil
+	case "warning":
+		return SeverityWarning, nil
+	}
+	return 0, fmt.Errorf("unknown severity %q", s)
+}
+
+//Finding is a problem reported by a rule
+type Finding struct {
+	RuleID   string   `json:"rule_id"`
+	Severity Severity `json:"severity"`
+	Path     string   `json:"path"`
+	Field    string   `json:"field,omitempty"`
+	Value    string   `json:"value,omitempty"`
+	Expected string   `json:"expected,omitempty"`
+	//Suspect tells what is more likely wrong when tags and file names disagree
+	Suspect string `json:"suspect,omitempty"`
+	//Suggested holds rewritten values by field when a fix spans several fields
+	Suggested map[string]string `json:"suggested,omitempty"`
+	//Pattern is the dictionary pattern a suspicious value matched
+	Pattern string `json:"pattern,omitempty"`
+	Message string `json:"message"`
+}
+
+//Suspect values of a finding
+const (
+	SuspectTag      = "tag"
+	SuspectFileName = "filename"
+)
+
+//TrackRule checks the tags of a single audio file.
+//Findings left without rule ID, severity or path get the rule ones.
+type TrackRule interface {
+	ID() string
+	Description() string
+	Severity() Severity
+	Check(track *TrackFile) []Finding
+}
+
+//AlbumRule checks the tags of all audio files of an album directory
+type AlbumRule interface {
+	ID() string
+	Description() string
+	Severity() Severity
+	Check(album *AlbumDir) []Finding
+}
+
+//LibraryRule checks the whole library at once, as rules comparing albums or artists do.
+//Findings should hold the path of the file or directory they are about.
+type LibraryRule interface {
+	ID() string
+	Description() string
+	Severity() Severity
+	Check(lib *Library) []Finding
+}
+
+//RuleInfo describes a rule to reporters
+type RuleInfo struct {
+	ID          string
+	Description string
+	Severity    Severity
+}
+
+var trackRules []TrackRule
+var albumRules []AlbumRule
+var libraryRules []LibraryRule
+
+//RegisterTrackRule adds a rule to the track rules run by the check command
+func RegisterTrackRule(r TrackRule) {
+	trackRules = append(trackRules, r)
+}
+
+//RegisterAlbumRule adds a rule to the album rules run by the check command
+func RegisterAlbumRule(r AlbumRule) {
+	albumRules = append(albumRules, r)
+}
+
+//RegisterLibraryRule adds a rule to the library rules run by the check command
+func RegisterLibraryRule(r LibraryRule) {
+	libraryRules = append(libraryRules, r)
+}
+
+//TrackRules returns registered track rules in registration order
+func TrackRules() []TrackRule {
+	return trackRules
+}
+
+//AlbumRules returns registered album rules in registration order
+func AlbumRules() []AlbumRule {
+	return albumRules
+}
+
+//LibraryRules returns registered library rules in registration order
+func LibraryRules() []LibraryRule {
+	return libraryRules
+}
+
+//Configurable is implemented by rules accepting options from the configuration file.
+//Configure returns a configured copy of the rule: registered rules are shared by every check.
+type Configurable interface {
+	Configure(options RuleOptions) (Configurable, error)
+}
+
+//TrackCheckFunc checks one audio file using the rule options
+type TrackCheckFunc func(track *TrackFile, options RuleOptions) []Finding
+
+//AlbumCheckFunc checks the audio files of an album directory using the rule options
+type AlbumCheckFunc func(album *AlbumDir, options RuleOptions) []Finding
+
+//LibraryCheckFunc checks the whole library using the rule options
+type LibraryCheckFunc func(lib *Library, options RuleOptions) []Finding
+
+type trackRuleFunc struct {
+	id          string
+	description string
+	severity    Severity
+	defaults    RuleOptions
+	options     RuleOptions
+	check       TrackCheckFunc
+}
+
+func (r *trackRuleFunc) ID() string          { return r.id }
+func (r *trackRuleFunc) Description() string { return r.description }
+func (r *trackRuleFunc) Severity() Severity  { return r.severity }
+
+func (r *trackRuleFunc) Configure(options RuleOptions) (Configurable, error) {
+	if err := options.checkDefaults(r.defaults); err != nil {
+		return nil, err
+	}
+
+	configured := *r
+	configured.options = options
+
+	return &configured, nil
+}
+
+func (r *trackRuleFunc) Check(track *TrackFile) []Finding {
+	return r.check(track, r.options)
+}
+
+//NewTrackRule builds a TrackRule from a check function
+func NewTrackRule(id string, description string, severity Severity, check TrackCheckFunc) TrackRule {
+	return &trackRuleFunc{id: id, description: description, severity: severity, check: check}
+}
+
+//NewTrackRuleWithOptions builds a TrackRule from a check function reading options.
+//defaults declares the options and their default values: other options and values of
+//another type are configuration errors, so check can ignore the option getters errors.
+func NewTrackRuleWithOptions(id string, description string, severity Severity, defaults RuleOptions, check TrackCheckFunc) TrackRule {
+	return &trackRuleFunc{id: id, description: description, severity: severity, defaults: defaults, check: check}
+}
+
+type albumRuleFunc struct {
+	id          string
+	description string
+	severity    Severity
+	defaults    RuleOptions
+	options     RuleOptions
+	check       AlbumCheckFunc
+}
+
+func (r *albumRuleFunc) ID() string          { return r.id }
+func (r *albumRuleFunc) Description() string { return r.description }
+func (r *albumRuleFunc) Severity() Severity  { return r.severity }
+
+func (r *albumRuleFunc) Configure(options RuleOptions) (Configurable, error) {
+	if err := options.checkDefaults(r.defaults); err != nil {
+		return nil, err
+	}
+
+	configured := *r
+	configured.options = options
+
+	return &configured, nil
+}
+
+func (r *albumRuleFunc) Check(album *AlbumDir) []Finding {
+	return r.check(album, r.options)
+}
+
+//NewAlbumRule builds an AlbumRule from a check function
+func NewAlbumRule(id string, description string, severity Severity, check AlbumCheckFunc) AlbumRule {
+	return &albumRuleFunc{id: id, description: description, severity: severity, check: check}
+}
+
+//NewAlbumRuleWithOptions builds an AlbumRule from a check function reading options.
+//defaults declares the options and their default values: other options and values of
+//another type are configuration errors, so check can ignore the option getters errors.
+func NewAlbumRuleWithOptions(id string, description string, severity Severity, defaults RuleOptions, check AlbumCheckFunc) AlbumRule {
+	return &albumRuleFunc{id: id, description: description, severity: severity, defaults: defaults, check: check}
+}
+
+type libraryRuleFunc struct {
+	id          string
+	description string
+	severity    Severity
+	defaults    RuleOptions
+	options     RuleOptions
+	check       LibraryCheckFunc
+}
+
+func (r *libraryRuleFunc) ID() string          { return r.id }
+func (r *libraryRuleFunc) Description() string { return r.description }
+func (r *libraryRuleFunc) Severity() Severity  { return r.severity }
+
+func (r *libraryRuleFunc) Configure(options RuleOptions) (Configurable, error) {
+	if err := options.checkDefaults(r.defaults); err != nil {
+		return nil, err
+	}
+
+	configured := *r
+	configured.options = options
+
+	return &configured, nil
+}
+
+func (r *libraryRuleFunc) Check(lib *Library) []Finding {
+	return r.check(lib, r.options)
+}
+
+//NewLibraryRule builds a LibraryRule from a check function
+func NewLibraryRule(id string, description string, severity Severity, check LibraryCheckFunc) LibraryRule {
+	return &libraryRuleFunc{id: id, description: description, severity: severity, check: check}
+}
+
+//NewLibraryRuleWithOptions builds a LibraryRule from a check function reading options.
+//defaults declares the options and their default values: other options and values of
+//another type are configuration errors, so check can ignore the option getters errors.
+func NewLibraryRuleWithOptions(id string, description string, severity Severity, defaults RuleOptions, check LibraryCheckFunc) LibraryRule {
+	return &libraryRuleFunc{id: id, description: description, severity: severity, defaults: defaults, check: check}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+)
+
+const sarifSchema = "https://json.schemastore.org/sarif-2.1.0.json"
+
+type sarifLog struct {
+	Schema  string     `json:"$schema"`
+	Version string     `json:"version"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                   `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLoc `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult               `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	Version        string      `json:"version"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID                   string             `json:"id"`
+	ShortDescription     sarifMessage       `json:"shortDescription"`
+	DefaultConfiguration sarifConfiguration `json:"defaultConfiguration"`
+}
+
+type sarifConfiguration struct {
+	Level string `json:"level"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID     string            `json:"ruleId"`
+	RuleIndex  *int              `json:"ruleIndex,omitempty"`
+	Level      string            `json:"level"`
+	Message    sarifMessage      `json:"message"`
+	Locations  []sarifLocation   `json:"locations"`
+	Properties map[string]string `json:"properties,omitempty"`
+}
+
+type sarifLocation struct {
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLoc `json:"artifactLocation"`
+}
+
+type sarifArtifactLoc struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+//sarifReporter writes findings as a SARIF 2.1.0 log
+type sarifReporter struct {
+	w         io.Writer
+	root      string
+	rules     []sarifRule
+	ruleIndex map[string]int
+	results   []sarifResult
+}
+
+func (r *sarifReporter) StartSection(section string, rules []RuleInfo) {
+	if r.ruleIndex == nil {
+		r.ruleIndex = map[string]int{}
+	}
+
+	for _, rule := range rules {
+		if _, exists := r.ruleIndex[rule.ID]; exists == true {
+			continue
+		}
+
+		r.ruleIndex[rule.ID] = len(r.rules)
+		r.rules = append(r.rules, sarifRule{
+			ID:                   rule.ID,
+			ShortDescription:     sarifMessage{rule.Description},
+			DefaultConfiguration: sarifConfiguration{sarifLevel(rule.Severity)},
+		})
+	}
+}
+
+func (r *sarifReporter) Report(section string, path string, findings []Finding) {
+	for _, f := range findings {
+		properties := map[string]string{}
+		if f.Field != "" {
+			properties["field"] = f.Field
+		}
+		if f.Value != "" {
+			properties["value"] = f.Value
+		}
+		if f.Expected != "" {
+			properties["expected"] = f.Expected
+		}
+		if f.Suspect != "" {
+			properties["suspect"] = f.Suspect
+		}
+		if f.Pattern != "" {
+			properties["pattern"] = f.Pattern
+		}
+		for field, value := range f.Suggested {
+			properties["suggested."+field] = value
+		}
+
+		//findings of rules missing from the log rules get no index rather than the first rule one
+		var ruleIndex *int
+		if index, ok := r.ruleIndex[f.RuleID]; ok == true {
+			ruleIndex = &index
+		}
+
+		r.results = append(r.results, sarifResult{
+			RuleID:    f.RuleID,
+			RuleIndex: ruleIndex,
+			Level:     sarifLevel(f.Severity),
+			Message:   sarifMessage{f.Message},
+			Locations: []sarifLocation{{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: sarifArtifactLoc{URI: relativeURI(r.root, f.Path), URIBaseID: "SRCROOT"},
+				},
+			}},
+			Properties: properties,
+		})
+	}
+}
+
+func (r *sarifReporter) LimitReached(section string) {}
+
+func (r *sarifReporter) EndSection(section string, summary SectionSummary) {}
+
+func (r *sarifReporter) Finish(summary Summary) error {
+	if r.results == nil {
+		r.results = []sarifResult{}
+	}
+	if r.rules == nil {
+		r.rules = []sarifRule{}
+	}
+
+	root, _ := filepath.Abs(r.root)
+
+	enc := json.NewEncoder(r.w)
+	enc.SetIndent("", "  ")
+
+	return enc.Encode(sarifLog{
+		Schema:  sarifSchema,
+		Version: "2.1.0",
+		Runs: []sarifRun{{
+			Tool: sarifTool{sarifDriver{
+				Name:           "audio-lib-tools",
+				Version:        version,
+				InformationURI: "https://github.com/mhor/audio-lib-tools",
+				Rules:          r.rules,
+			}},
+			OriginalURIBaseIDs: map[string]sarifArtifactLoc{
+				"SRCROOT": {URI: "file://" + filepath.ToSlash(root) + "/"},
+			},
+			Results: r.results,
+		}},
+	})
+}
+
+func sarifLevel(s Severity) string {
+	if s == SeverityError {
+		return "error"
+	}
+
+	return "warning"
+}
+
+//relativeURI returns path relative to root with forward slashes
+func relativeURI(root string, path string) string {
+	rel, err := filepath.Rel(root, path)
+	if err != nil {
+		return filepath.ToSlash(path)
+	}
+
+	return filepath.ToSlash(rel)
+}
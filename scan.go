@@ -0,0 +1,213 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	tag "github.com/dhowden/tag"
+)
+
+//TrackFile is an audio file and its tags
+type TrackFile struct {
+	Path string
+	*trackTags
+	library *Library
+}
+
+//AlbumDir is a directory directly holding audio files
+type AlbumDir struct {
+	Path    string
+	Tracks  []*TrackFile
+	library *Library
+}
+
+//AlbumName returns the album tag of the first track
+func (a *AlbumDir) AlbumName() string {
+	if len(a.Tracks) == 0 {
+		return ""
+	}
+
+	return a.Tracks[0].Album()
+}
+
+//ReleaseDirs returns the album directories holding the same album as a, a included:
+//a and its sibling directories with the same album tag, as CD1/CD2 directories are.
+//Directories without album tag are never grouped. Directories are in walk order.
+func (a *AlbumDir) ReleaseDirs() []*AlbumDir {
+	if a.library == nil || sanitizeString(a.AlbumName()) == "" {
+		return []*AlbumDir{a}
+	}
+
+	var dirs []*AlbumDir
+	for _, dir := range a.library.albumsByParent[filepath.Dir(a.Path)] {
+		if dir == a || sanitizeString(dir.AlbumName()) == sanitizeString(a.AlbumName()) {
+			dirs = append(dirs, dir)
+		}
+	}
+
+	return dirs
+}
+
+//Library is the in-memory index of a scanned root. Every audio file is read once.
+type Library struct {
+	Root        string
+	Tracks      []*TrackFile
+	Albums      []*AlbumDir
+	IgnoreFiles []string
+	//ReadErrors counts audio files that could not be opened or read; files with unreadable tags are skipped but not counted
+	ReadErrors int
+
+	albumsByParent map[string][]*AlbumDir
+	//variousArtists and suspiciousMatchers come from the configuration of the running check
+	variousArtists     *variousArtistsDictionary
+	suspiciousMatchers map[string]suspiciousMatcher
+}
+
+//suspiciousMatcher returns the matcher of the suspicious rule checking field in the running
+//check, or the built-in one when no rule checks field or lib is nil
+func (lib *Library) suspiciousMatcher(field string) suspiciousMatcher {
+	if lib != nil {
+		if matcher, exists := lib.suspiciousMatchers[field]; exists == true {
+			return matcher
+		}
+	}
+
+	return defaultSuspiciousMatcher
+}
+
+//scanLibrary walks root and reads the tags of every audio file with jobs workers.
+//Files unchanged since they were stored in cache are not read again; cache may be nil.
+//Tracks and albums are kept in walk order.
+func scanLibrary(root string, jobs int, cache *scanCache) (*Library, error) {
+	lib := &Library{Root: root, albumsByParent: map[string][]*AlbumDir{}}
+
+	var paths []string
+	var infos []os.FileInfo
+	err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() == true {
+			return nil
+		}
+
+		if info.Name() == ignoreFileName {
+			lib.IgnoreFiles = append(lib.IgnoreFiles, path)
+			return nil
+		}
+
+		if isAudioFile(filepath.Ext(path)) == false {
+			return nil
+		}
+
+		paths = append(paths, path)
+		infos = append(infos, info)
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	albums := map[string]*AlbumDir{}
+	tracks := make([]*TrackFile, len(paths))
+	errs := make([]error, len(paths))
+	cached := make([]bool, len(paths))
+	var cacheErr error
+
+	forEachOrdered(len(paths), jobs, func(i int) {
+		if cache != nil {
+			if t, ok := cache.get(paths[i], infos[i]); ok == true {
+				tracks[i] = &TrackFile{Path: paths[i], trackTags: t}
+				cached[i] = true
+				return
+			}
+		}
+
+		t, err := readTrackTags(paths[i], infos[i].Size())
+		if err != nil {
+			errs[i] = err
+			return
+		}
+
+		tracks[i] = &TrackFile{Path: paths[i], trackTags: t}
+	}, func(i int) bool {
+		if errs[i] != nil {
+			if isReadError(errs[i]) == true {
+				fmt.Fprintf(os.Stderr, "error reading file %s: %v\n", paths[i], errs[i])
+				lib.ReadErrors++
+			} else {
+				fmt.Fprintf(os.Stderr, "skipping file %s: %v\n", paths[i], errs[i])
+			}
+			return true
+		}
+
+		track := tracks[i]
+		track.library = lib
+		lib.Tracks = append(lib.Tracks, track)
+
+		if cache != nil && cached[i] == false && cacheErr == nil {
+			cacheErr = cache.put(track.Path, infos[i], track.trackTags)
+		}
+
+		dir := filepath.Dir(track.Path)
+		album, exists := albums[dir]
+		if exists == false {
+			album = &AlbumDir{Path: dir, library: lib}
+			albums[dir] = album
+			lib.Albums = append(lib.Albums, album)
+			lib.albumsByParent[filepath.Dir(dir)] = append(lib.albumsByParent[filepath.Dir(dir)], album)
+		}
+		album.Tracks = append(album.Tracks, track)
+
+		return true
+	})
+
+	if cache != nil && cacheErr == nil {
+		cacheErr = cache.flush()
+	}
+
+	if cacheErr != nil {
+		fmt.Fprintf(os.Stderr, "error writing cache: %v\n", cacheErr)
+	}
+
+	return lib, nil
+}
+
+//isReadError tells if err comes from opening or reading a file rather than from its content
+func isReadError(err error) bool {
+	var pathErr *os.PathError
+	return errors.As(err, &pathErr) == true || errors.Is(err, io.ErrUnexpectedEOF) == true
+}
+
+//readTrackTags reads the tags and the stream properties of an audio file of size bytes.
+//Untagged files get empty tags, reported by the missing tag rules. Unreadable stream
+//properties are left empty, as tags are still worth checking.
+func readTrackTags(path string, size int64) (*trackTags, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	var t *trackTags
+	m, err := tag.ReadFrom(file)
+	switch {
+	case err == tag.ErrNoTagsFound:
+		t = newEmptyTrackTags()
+	case err != nil:
+		return nil, err
+	default:
+		t = newTrackTags(m)
+	}
+
+	if _, err := file.Seek(0, io.SeekStart); err == nil {
+		t.v.Audio, _ = readAudioInfo(file, size, filepath.Ext(path))
+	}
+
+	return t, nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"fmt"
+
+	tag "github.com/dhowden/tag"
+	"github.com/dhowden/tag/mbz"
+)
+
+//tagValues is a copy of the tags of an audio file, without picture data,
+//so a whole library can be held in memory
+type tagValues struct {
+	Format      tag.Format        `json:"format"`
+	FileType    tag.FileType      `json:"file_type"`
+	Title       string            `json:"title"`
+	Album       string            `json:"album"`
+	Artist      string            `json:"artist"`
+	AlbumArtist string            `json:"album_artist"`
+	Composer    string            `json:"composer"`
+	Genre       string            `json:"genre"`
+	Year        int               `json:"year"`
+	Track       int               `json:"track"`
+	TrackTotal  int               `json:"track_total"`
+	Disc        int               `json:"disc"`
+	DiscTotal   int               `json:"disc_total"`
+	Lyrics      string            `json:"lyrics"`
+	Comment     string            `json:"comment"`
+	Picture     *tag.Picture      `json:"picture,omitempty"`
+	Cover       *coverInfo        `json:"cover,omitempty"`
+	Audio       *audioInfo        `json:"audio,omitempty"`
+	Raw         map[string]string `json:"raw"`
+	MusicBrainz map[string]string `json:"musicbrainz"`
+}
+
+//trackTags implements tag.Metadata over a tagValues copy
+type trackTags struct {
+	v tagValues
+}
+
+func newTrackTags(m tag.Metadata) *trackTags {
+	v := tagValues{
+		Format:      m.Format(),
+		FileType:    m.FileType(),
+		Title:       m.Title(),
+		Album:       m.Album(),
+		Artist:      m.Artist(),
+		AlbumArtist: m.AlbumArtist(),
+		Composer:    m.Composer(),
+		Genre:       m.Genre(),
+		Year:        m.Year(),
+		Lyrics:      m.Lyrics(),
+		Comment:     m.Comment(),
+		Raw:         map[string]string{},
+		MusicBrainz: map[string]string(mbz.Extract(m)),
+	}
+
+	v.Track, v.TrackTotal = m.Track()
+	v.Disc, v.DiscTotal = m.Disc()
+
+	if p := m.Picture(); p != nil {
+		v.Picture = &tag.Picture{Ext: p.Ext, MIMEType: p.MIMEType, Type: p.Type, Description: p.Description}
+		v.Cover = decodeCover(p)
+	}
+
+	for k, raw := range m.Raw() {
+		//compilation flags are kept as "1" or "0" whatever their raw type
+		if containsFold(compilationTags, k) == true {
+			v.Raw[k] = "0"
+			if compilationFlag(raw) == true {
+				v.Raw[k] = "1"
+			}
+			continue
+		}
+
+		switch value := raw.(type) {
+		case string, int, bool:
+			v.Raw[k] = fmt.Sprint(value)
+		case *tag.Comm:
+			v.Raw[k] = value.Text
+		}
+	}
+
+	return &trackTags{v}
+}
+
+//newEmptyTrackTags returns the tags of an untagged file
+func newEmptyTrackTags() *trackTags {
+	return &trackTags{tagValues{Raw: map[string]string{}, MusicBrainz: map[string]string{}}}
+}
+
+func (t *trackTags) Format() tag.Format     { return t.v.Format }
+func (t *trackTags) FileType() tag.FileType { return t.v.FileType }
+func (t *trackTags) Title() string          { return t.v.Title }
+func (t *trackTags) Album() string          { return t.v.Album }
+func (t *trackTags) Artist() string         { return t.v.Artist }
+func (t *trackTags) AlbumArtist() string    { return t.v.AlbumArtist }
+func (t *trackTags) Composer() string       { return t.v.Composer }
+func (t *trackTags) Genre() string          { return t.v.Genre }
+func (t *trackTags) Year() int              { return t.v.Year }
+func (t *trackTags) Track() (int, int)      { return t.v.Track, t.v.TrackTotal }
+func (t *trackTags) Disc() (int, int)       { return t.v.Disc, t.v.DiscTotal }
+func (t *trackTags) Lyrics() string         { return t.v.Lyrics }
+func (t *trackTags) Comment() string        { return t.v.Comment }
+
+//Picture returns the picture description; the image data is not kept
+func (t *trackTags) Picture() *tag.Picture { return t.v.Picture }
+
+//Cover returns what decoding the picture data found, nil without picture
+func (t *trackTags) Cover() *coverInfo { return t.v.Cover }
+
+//Audio returns the stream properties, nil when they could not be read
+func (t *trackTags) Audio() *audioInfo { return t.v.Audio }
+
+//Raw returns the textual raw tags
+func (t *trackTags) Raw() map[string]interface{} {
+	raw := map[string]interface{}{}
+	for k, v := range t.v.Raw {
+		raw[k] = v
+	}
+
+	return raw
+}
+
+//MusicBrainz returns the MusicBrainz identifiers read by mbz.Extract
+func (t *trackTags) MusicBrainz() mbz.Info {
+	return mbz.Info(t.v.MusicBrainz)
+}
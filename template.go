@@ -0,0 +1,333 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/dhowden/tag/mbz"
+)
+
+//templateFields are the placeholders a naming template knows, one per TrackFlat field
+var templateFields = []string{
+	"track", "track_total", "disc", "disc_total",
+	"title", "album", "artist", "album_artist",
+	"year", "release_date",
+	"mb_track_uuid", "mb_track_artist_uuid", "mb_album_artist_uuid", "mb_album_uuid",
+	"ext",
+}
+
+var numericTemplateFields = []string{"track", "track_total", "disc", "disc_total", "year"}
+
+//forbiddenFileNameChars cannot be used in file names on at least one common filesystem
+const forbiddenFileNameChars = `<>:"/\|?*`
+
+//templateSegment is a literal, a {field:width} placeholder or an optional [...] part
+type templateSegment struct {
+	literal  string
+	field    string
+	width    int
+	optional []templateSegment
+}
+
+//namingTemplate matches names against a pattern such as "[{disc}-]{track:02} {title}.{ext}"
+type namingTemplate struct {
+	pattern  string
+	segments []templateSegment
+	re       *regexp.Regexp
+	//fields holds the placeholder of every regexp group
+	fields []string
+	//minDepth and maxDepth are the number of path components the template spans
+	minDepth int
+	maxDepth int
+}
+
+//templateMismatch is a placeholder whose value in a name differs from the tag value
+type templateMismatch struct {
+	field     string
+	tagValue  string
+	nameValue string
+}
+
+func parseNamingTemplate(pattern string) (*namingTemplate, error) {
+	segments, rest, err := parseTemplateSegments(pattern, false)
+	if err != nil {
+		return nil, err
+	}
+	if rest != "" {
+		return nil, fmt.Errorf("template %q: unexpected %q", pattern, rest)
+	}
+
+	t := &namingTemplate{pattern: pattern, segments: segments}
+
+	re, err := regexp.Compile("^" + t.regexp(segments) + "$")
+	if err != nil {
+		return nil, fmt.Errorf("template %q: %v", pattern, err)
+	}
+	t.re = re
+
+	for _, seg := range segments {
+		t.minDepth += strings.Count(seg.literal, "/")
+		for _, inner := range seg.optional {
+			t.maxDepth += strings.Count(inner.literal, "/")
+		}
+	}
+	t.minDepth++
+	t.maxDepth += t.minDepth
+
+	return t, nil
+}
+
+func parseTemplateSegments(s string, optional bool) ([]templateSegment, string, error) {
+	var segments []templateSegment
+	var literal strings.Builder
+
+	flush := func() {
+		if literal.Len() > 0 {
+			segments = append(segments, templateSegment{literal: literal.String()})
+			literal.Reset()
+		}
+	}
+
+	for len(s) > 0 {
+		switch s[0] {
+		case '{':
+			end := strings.IndexByte(s, '}')
+			if end < 0 {
+				return nil, "", fmt.Errorf("unclosed placeholder in %q", s)
+			}
+
+			field, width := s[1:end], 0
+			if i := strings.IndexByte(field, ':'); i >= 0 {
+				w, err := strconv.Atoi(field[i+1:])
+				if err != nil {
+					return nil, "", fmt.Errorf("bad width in {%s}", field)
+				}
+				field, width = field[:i], w
+			}
+
+			if containsString(templateFields, field) == false {
+				return nil, "", fmt.Errorf("unknown placeholder {%s}", field)
+			}
+
+			flush()
+			segments = append(segments, templateSegment{field: field, width: width})
+			s = s[end+1:]
+		case '[':
+			if optional == true {
+				return nil, "", fmt.Errorf("nested optional part in %q", s)
+			}
+
+			flush()
+			inner, rest, err := parseTemplateSegments(s[1:], true)
+			if err != nil {
+				return nil, "", err
+			}
+			if strings.HasPrefix(rest, "]") == false {
+				return nil, "", fmt.Errorf("unclosed optional part in %q", s)
+			}
+
+			segments = append(segments, templateSegment{optional: inner})
+			s = rest[1:]
+		case ']':
+			if optional == false {
+				return nil, "", fmt.Errorf("unexpected ] in %q", s)
+			}
+
+			flush()
+			return segments, s, nil
+		default:
+			literal.WriteByte(s[0])
+			s = s[1:]
+		}
+	}
+
+	flush()
+
+	return segments, "", nil
+}
+
+func (t *namingTemplate) regexp(segments []templateSegment) string {
+	var re strings.Builder
+	for _, seg := range segments {
+		switch {
+		case seg.optional != nil:
+			re.WriteString("(?:" + t.regexp(seg.optional) + ")?")
+		case seg.field == "ext":
+			t.fields = append(t.fields, seg.field)
+			re.WriteString(`([^./]+)`)
+		case containsString(numericTemplateFields, seg.field):
+			t.fields = append(t.fields, seg.field)
+			re.WriteString(`(\d+)`)
+		case seg.field != "":
+			t.fields = append(t.fields, seg.field)
+			re.WriteString(`([^/]+)`)
+		default:
+			re.WriteString(regexp.QuoteMeta(seg.literal))
+		}
+	}
+
+	return re.String()
+}
+
+//render builds the name the template gives for values; optional parts with an empty value are left out
+//and other empty values are rendered as their placeholder
+func (t *namingTemplate) render(values map[string]string) string {
+	s, _ := renderTemplateSegments(t.segments, values)
+	return s
+}
+
+func renderTemplateSegments(segments []templateSegment, values map[string]string) (string, bool) {
+	var s strings.Builder
+	var complete = true
+	for _, seg := range segments {
+		switch {
+		case seg.optional != nil:
+			if part, ok := renderTemplateSegments(seg.optional, values); ok == true {
+				s.WriteString(part)
+			}
+		case seg.field != "":
+			value := values[seg.field]
+			if value == "" || value == "0" {
+				//keep the placeholder so the missing tag shows up
+				complete = false
+				s.WriteString("{" + seg.field + "}")
+				continue
+			}
+			if n, err := strconv.Atoi(value); err == nil && seg.width > 0 {
+				value = fmt.Sprintf("%0*d", seg.width, n)
+			}
+			s.WriteString(sanitizeFileName(value))
+		default:
+			s.WriteString(seg.literal)
+		}
+	}
+
+	return s.String(), complete
+}
+
+//compare matches name against the template and returns the placeholders whose value differs from values
+func (t *namingTemplate) compare(name string, values map[string]string, ignoreCase bool) (bool, []templateMismatch) {
+	groups := t.re.FindStringSubmatch(name)
+	if groups == nil {
+		return false, nil
+	}
+
+	var mismatches []templateMismatch
+	for i, field := range t.fields {
+		nameValue := groups[i+1]
+		tagValue := values[field]
+
+		if nameValue == "" {
+			//optional part left out of the name
+			continue
+		}
+
+		var same bool
+		if containsString(numericTemplateFields, field) == true {
+			n, _ := strconv.Atoi(nameValue)
+			v, _ := strconv.Atoi(tagValue)
+			same = n == v
+		} else {
+			same = fileNameMatches(nameValue, tagValue, ignoreCase)
+		}
+
+		if same == false {
+			mismatches = append(mismatches, templateMismatch{field: field, tagValue: tagValue, nameValue: nameValue})
+		}
+	}
+
+	return true, mismatches
+}
+
+//sanitizeFileName replaces characters file names cannot hold
+func sanitizeFileName(s string) string {
+	return strings.Map(func(r rune) rune {
+		if strings.ContainsRune(forbiddenFileNameChars, r) || unicode.IsControl(r) {
+			return '_'
+		}
+		return r
+	}, s)
+}
+
+//fileNameMatches compares a name part with a tag value. Characters file names cannot hold
+//may be replaced by any character or left out, and trailing dots and spaces may be dropped.
+func fileNameMatches(name string, value string, ignoreCase bool) bool {
+	nameRunes := []rune(strings.TrimRight(name, ". "))
+	value = strings.TrimRight(value, ". ")
+
+	//reachable holds the name positions the value read so far can end at
+	reachable := make([]bool, len(nameRunes)+1)
+	reachable[0] = true
+	for _, r := range value {
+		next := make([]bool, len(nameRunes)+1)
+		forbidden := strings.ContainsRune(forbiddenFileNameChars, r) || unicode.IsControl(r)
+		for i, ok := range reachable {
+			if ok == false {
+				continue
+			}
+			if forbidden == true {
+				next[i] = true
+			}
+			if i < len(nameRunes) && (forbidden == true || sameRune(nameRunes[i], r, ignoreCase) == true) {
+				next[i+1] = true
+			}
+		}
+		reachable = next
+	}
+
+	return reachable[len(nameRunes)]
+}
+
+//sameRune compares runes, through simple case folding when ignoreCase is set
+func sameRune(a rune, b rune, ignoreCase bool) bool {
+	if a == b {
+		return true
+	}
+	if ignoreCase == false {
+		return false
+	}
+
+	for f := unicode.SimpleFold(a); f != a; f = unicode.SimpleFold(f) {
+		if f == b {
+			return true
+		}
+	}
+
+	return false
+}
+
+//trackTemplateValues returns the placeholder values of a track, numbers left empty when unset
+func trackTemplateValues(track *TrackFile) map[string]string {
+	number, trackTotal := track.Track()
+	disc, discTotal := track.Disc()
+	mbTags := track.MusicBrainz()
+
+	itoa := func(n int) string {
+		if n == 0 {
+			return ""
+		}
+		return strconv.Itoa(n)
+	}
+
+	return map[string]string{
+		"track":                itoa(number),
+		"track_total":          itoa(trackTotal),
+		"disc":                 itoa(disc),
+		"disc_total":           itoa(discTotal),
+		"title":                track.Title(),
+		"album":                track.Album(),
+		"artist":               track.Artist(),
+		"album_artist":         track.AlbumArtist(),
+		"year":                 itoa(track.Year()),
+		"release_date":         releaseDate(track),
+		"mb_track_uuid":        mbTags.Get(mbz.Track),
+		"mb_track_artist_uuid": mbTags.Get(mbz.Artist),
+		"mb_album_artist_uuid": mbTags.Get(mbz.AlbumArtist),
+		"mb_album_uuid":        mbTags.Get(mbz.Album),
+		"ext":                  strings.TrimPrefix(filepath.Ext(track.Path), "."),
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseNamingTemplate(t *testing.T) {
+	tests := []struct {
+		pattern  string
+		fields   []string
+		minDepth int
+		maxDepth int
+	}{
+		{"{track:02} - {title}.{ext}", []string{"track", "title", "ext"}, 1, 1},
+		{"[{disc}-]{track:02} {title}.{ext}", []string{"disc", "track", "title", "ext"}, 1, 1},
+		{"{album_artist}/{year} - {album}[/CD{disc}]", []string{"album_artist", "year", "album", "disc"}, 2, 3},
+		{"({year}) {album}+", []string{"year", "album"}, 1, 1},
+	}
+
+	for _, test := range tests {
+		tmpl, err := parseNamingTemplate(test.pattern)
+		if err != nil {
+			t.Errorf("%s: unexpected error %v", test.pattern, err)
+			continue
+		}
+		if reflect.DeepEqual(tmpl.fields, test.fields) == false {
+			t.Errorf("%s: fields %v, want %v", test.pattern, tmpl.fields, test.fields)
+		}
+		if tmpl.minDepth != test.minDepth || tmpl.maxDepth != test.maxDepth {
+			t.Errorf("%s: depth %d-%d, want %d-%d", test.pattern, tmpl.minDepth, tmpl.maxDepth, test.minDepth, test.maxDepth)
+		}
+	}
+}
+
+func TestParseNamingTemplateErrors(t *testing.T) {
+	for _, pattern := range []string{
+		"{track",
+		"{unknown}",
+		"{track:xx}",
+		"[{disc}-",
+		"[a[{disc}]]",
+		"{title}]",
+	} {
+		if _, err := parseNamingTemplate(pattern); err == nil {
+			t.Errorf("%s: expected an error", pattern)
+		}
+	}
+}
+
+func TestNamingTemplateRender(t *testing.T) {
+	tests := []struct {
+		pattern string
+		values  map[string]string
+		want    string
+	}{
+		{"{track:02} - {title}.{ext}", map[string]string{"track": "1", "title": "Song", "ext": "mp3"}, "01 - Song.mp3"},
+		{"{track:03}", map[string]string{"track": "7"}, "007"},
+		{"{track:01}", map[string]string{"track": "12"}, "12"},
+		{"{track}", map[string]string{"track": "07"}, "07"},
+		//optional parts are left out when a value is missing
+		{"[{disc}-]{track:02} {title}.{ext}", map[string]string{"track": "1", "title": "Song", "ext": "mp3"}, "01 Song.mp3"},
+		{"[{disc}-]{track:02} {title}.{ext}", map[string]string{"disc": "2", "track": "1", "title": "Song", "ext": "mp3"}, "2-01 Song.mp3"},
+		//missing required values keep their placeholder
+		{"{track:02} {title}.{ext}", map[string]string{"track": "1", "ext": "mp3"}, "01 {title}.mp3"},
+		{"{track:02} {title}.{ext}", map[string]string{"track": "0", "title": "Song", "ext": "mp3"}, "{track} Song.mp3"},
+		//forbidden characters are replaced, literals are kept as they are
+		{"{title}.{ext}", map[string]string{"title": "AC/DC: Live?", "ext": "flac"}, "AC_DC_ Live_.flac"},
+		{"({year}) {album}+.{ext}", map[string]string{"year": "1999", "album": "Album", "ext": "flac"}, "(1999) Album+.flac"},
+	}
+
+	for _, test := range tests {
+		tmpl, err := parseNamingTemplate(test.pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.pattern, err)
+		}
+		if got := tmpl.render(test.values); got != test.want {
+			t.Errorf("%s: rendered %q, want %q", test.pattern, got, test.want)
+		}
+	}
+}
+
+func TestNamingTemplateCompare(t *testing.T) {
+	song := map[string]string{"disc": "2", "track": "1", "title": "Song", "ext": "mp3"}
+	acdc := map[string]string{"track": "1", "title": "AC/DC", "ext": "mp3"}
+
+	tests := []struct {
+		pattern    string
+		name       string
+		values     map[string]string
+		ignoreCase bool
+		matched    bool
+		mismatches []string
+	}{
+		{"{track:02}. {title} (live).{ext}", "01. Song (live).mp3", song, false, true, nil},
+		//literal dots and parentheses are not regexp wildcards
+		{"{track:02}. {title} (live).{ext}", "01x Song (live)xmp3", song, false, false, nil},
+		{"{track:02}. {title} (live).{ext}", "01. Song live.mp3", song, false, false, nil},
+		{"{track:02}. {title} (live).{ext}", "02. Song (live).mp3", song, false, true, []string{"track"}},
+		//numbers compare whatever the padding
+		{"{track:02}. {title} (live).{ext}", "1. Song (live).mp3", song, false, true, nil},
+		{"{track:02} {title}.{ext}", "01 song.mp3", song, true, true, nil},
+		{"{track:02} {title}.{ext}", "01 song.mp3", song, false, true, []string{"title"}},
+		{"{track:02} {title}.{ext}", "01 AC_DC.mp3", acdc, false, true, nil},
+		{"{track:02} {title}.{ext}", "01 ACDC.mp3", acdc, false, true, nil},
+		{"{track:02} {title}.{ext}", "01 AC-DC.mp3", acdc, false, true, nil},
+		{"{track:02} {title}.{ext}", "01 AC--DC.mp3", acdc, false, true, []string{"title"}},
+		//optional parts left out of the name are not compared
+		{"[{disc}-]{track:02} {title}.{ext}", "01 Song.mp3", song, false, true, nil},
+		{"[{disc}-]{track:02} {title}.{ext}", "1-01 Song.mp3", song, false, true, []string{"disc"}},
+	}
+
+	for _, test := range tests {
+		tmpl, err := parseNamingTemplate(test.pattern)
+		if err != nil {
+			t.Fatalf("%s: unexpected error %v", test.pattern, err)
+		}
+
+		matched, mismatches := tmpl.compare(test.name, test.values, test.ignoreCase)
+		var fields []string
+		for _, m := range mismatches {
+			fields = append(fields, m.field)
+		}
+
+		if matched != test.matched || reflect.DeepEqual(fields, test.mismatches) == false {
+			t.Errorf("%s against %s: matched %v with mismatches %v, want %v with %v", test.name, test.pattern, matched, fields, test.matched, test.mismatches)
+		}
+	}
+}
+
+func TestFileNameMatches(t *testing.T) {
+	tests := []struct {
+		name       string
+		value      string
+		ignoreCase bool
+		want       bool
+	}{
+		{"Song", "Song", false, true},
+		{"Song a", "Song", false, false},
+		{"Song", "Song a", false, false},
+		{"AC_DC", "AC/DC", false, true},
+		{"ACDC", "AC/DC", false, true},
+		{"AC--DC", "AC/DC", false, false},
+		{"Why", "Why?", false, true},
+		{"Why_", "Why?", false, true},
+		//trailing dots and spaces may be dropped
+		{"Song", "Song.", false, true},
+		{"Song. ", "Song", false, true},
+		{"ÉTÉ", "été", true, true},
+		{"ÉTÉ", "été", false, false},
+		{"", "", false, true},
+		{"", "?", false, true},
+	}
+
+	for _, test := range tests {
+		if got := fileNameMatches(test.name, test.value, test.ignoreCase); got != test.want {
+			t.Errorf("fileNameMatches(%q, %q, %v) = %v, want %v", test.name, test.value, test.ignoreCase, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"io"
+	"os"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+//variousArtistsData is the built-in list of Various Artists names
+//go:embed data/various_artists.txt
+var variousArtistsData []byte
+
+//defaultVariousArtistsDistance is the edit distance allowed by default between a name
+//and a Various Artists name
+const defaultVariousArtistsDistance = 2
+
+//fuzzyVariousArtistsLength is the shortest key matched by edit distance: shorter names,
+//as "Divers" and "Rivers", are too close to real artist names
+const fuzzyVariousArtistsLength = 8
+
+//defaultVariousArtists is the built-in Various Artists dictionary; configuration files
+//extending it get their own copy
+var defaultVariousArtists = newVariousArtistsDictionary()
+
+//variousArtistsDictionary is a set of Various Artists names by matching key
+type variousArtistsDictionary struct {
+	names map[string]string
+	//maxDistance is the edit distance allowed between a name and a dictionary name
+	maxDistance int
+	//matches memoizes fuzzy lookups by value and distance, album artists repeat a lot
+	matches *sync.Map
+}
+
+type variousArtistsLookup struct {
+	value       string
+	maxDistance int
+}
+
+func newVariousArtistsDictionary() *variousArtistsDictionary {
+	d := &variousArtistsDictionary{names: map[string]string{}, maxDistance: defaultVariousArtistsDistance, matches: &sync.Map{}}
+	d.read(bytes.NewReader(variousArtistsData))
+
+	return d
+}
+
+//variousArtistsKey folds case, compatibility forms, spaces and punctuation:
+//"V/A", "V. A." and "Ｖ．Ａ." are all "va"
+func variousArtistsKey(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsLetter(r) == false && unicode.IsDigit(r) == false {
+			return -1
+		}
+		return unicode.ToLower(r)
+	}, normalizeText(s))
+}
+
+//read adds the names of a list, one per line, "#" starting comments
+func (d *variousArtistsDictionary) read(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		name := strings.TrimSpace(scanner.Text())
+		if name == "" || strings.HasPrefix(name, "#") == true {
+			continue
+		}
+
+		if key := variousArtistsKey(name); key != "" {
+			if _, exists := d.names[key]; exists == false {
+				d.names[key] = name
+			}
+		}
+	}
+	d.matches = &sync.Map{}
+
+	return scanner.Err()
+}
+
+//load adds the names of a user file to the dictionary
+func (d *variousArtistsDictionary) load(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return d.read(f)
+}
+
+//match returns the dictionary name s stands for, exactly once folded or by edit distance
+func (d *variousArtistsDictionary) match(s string) (string, bool) {
+	key, maxDistance := variousArtistsKey(s), d.maxDistance
+	if key == "" {
+		return "", false
+	}
+
+	if name, exists := d.names[key]; exists == true {
+		return name, true
+	}
+
+	if maxDistance <= 0 || len([]rune(key)) < fuzzyVariousArtistsLength {
+		return "", false
+	}
+
+	lookup := variousArtistsLookup{key, maxDistance}
+	if name, exists := d.matches.Load(lookup); exists == true {
+		return name.(string), name.(string) != ""
+	}
+
+	//one edit per six characters, so short names stay strict
+	allowed := len([]rune(key)) / 6
+	if allowed > maxDistance {
+		allowed = maxDistance
+	}
+
+	var best string
+	bestDistance := allowed + 1
+	for candidate, name := range d.names {
+		if len([]rune(candidate)) < fuzzyVariousArtistsLength {
+			continue
+		}
+
+		distance := editDistance(key, candidate)
+		if distance < bestDistance || (distance == bestDistance && name < best) {
+			best, bestDistance = name, distance
+		}
+	}
+	if bestDistance > allowed {
+		best = ""
+	}
+	d.matches.Store(lookup, best)
+
+	return best, best != ""
+}
+
+//editDistance returns the Levenshtein distance between a and b, in runes
+func editDistance(a string, b string) int {
+	ra, rb := []rune(a), []rune(b)
+
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = minInt(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+
+	return previous[len(rb)]
+}
+
+func minInt(values ...int) int {
+	min := values[0]
+	for _, v := range values[1:] {
+		if v < min {
+			min = v
+		}
+	}
+
+	return min
+}
+
+//isVariousArtists tells if s is a Various Artists name, misspellings included,
+//through the dictionary of the check configuration or the built-in one when lib is nil
+func (lib *Library) isVariousArtists(s string) bool {
+	d := defaultVariousArtists
+	if lib != nil && lib.variousArtists != nil {
+		d = lib.variousArtists
+	}
+	_, ok := d.match(s)
+
+	return ok
+}